@@ -0,0 +1,21 @@
+package mcts
+
+import (
+	"CloudWars/bot"
+	"log"
+	"time"
+)
+
+// RunMCTS is an AI that accesses a game server with the remote.TcpClient and controls a player cloud.
+// The bot searches budget per poll (10 times per second, 0: 80 ms) and moves every 0.5 seconds (see MCTS).
+func RunMCTS(host, port, name, color string, budget time.Duration) {
+	ai := NewMCTS(name, color)
+	ai.Verbose = true
+	if budget > 0 {
+		ai.Budget = budget
+	}
+	if err := bot.RunTcp(host, port, ai); err != nil {
+		log.Fatal(err)
+	}
+	println("END", name)
+}
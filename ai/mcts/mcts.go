@@ -0,0 +1,245 @@
+package mcts
+
+import (
+	"CloudWars/bot"
+	"CloudWars/core"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// MCTS is a Monte Carlo Tree Search AI as bot (see bot.Bot).
+// The tree searches sequences of move commands (including no move) of Step seconds each on clones of the world,
+// the opponents follow the Policy. The search continues on every call of Act until the next step starts,
+// then the most visited move is executed and its subtree is the tree of the next step.
+type MCTS struct {
+	name  string
+	color string
+	rnd   *rand.Rand
+
+	Angles      int           // directions of the move commands (DEFAULT: 8)
+	Strengths   []float32     // wind strengths in percent of the own vapor (DEFAULT: 3, 10, 25)
+	Step        float64       // game time between two moves in seconds (DEFAULT: 0.5)
+	Depth       int           // moves per sequence, the rest of the horizon is no move (DEFAULT: 4)
+	Exploration float64       // UCT constant (DEFAULT: 0.3)
+	Budget      time.Duration // max thinking time per call of Act (DEFAULT: 80 ms)
+	Iterations  int           // max iterations per call of Act (DEFAULT: 0 = budget only)
+	SimSpeedUp  int           // larger simulation steps (DEFAULT: 10)
+	SubStepping bool          // no tunnelling in the simulations, but SimSpeedUp times slower (DEFAULT: false)
+	Policy      bot.Policy    // opponent model (DEFAULT: bot.Greedy)
+	Verbose     bool          // log the decisions
+
+	root *node
+	next uint64 // iteration of the next move (root of the tree)
+}
+
+// NewMCTS creates a MCTS bot for a player with the default settings.
+func NewMCTS(name, color string) *MCTS {
+	return &MCTS{
+		name:        name,
+		color:       color,
+		rnd:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		Angles:      8,
+		Strengths:   []float32{3, 10, 25},
+		Step:        0.5,
+		Depth:       4,
+		Exploration: 0.3,
+		Budget:      80 * time.Millisecond,
+		SimSpeedUp:  10,
+		Policy:      bot.NewGreedy(),
+	}
+}
+
+// Seed makes the search reproducible (with Iterations and without Budget).
+func (m *MCTS) Seed(seed int64) {
+	m.rnd = rand.New(rand.NewSource(seed))
+}
+
+func (m *MCTS) Name() string {
+	return m.name
+}
+
+func (m *MCTS) Color() string {
+	return m.color
+}
+
+// Act continues the search and returns the best move command when the next step starts.
+func (m *MCTS) Act(world *core.World) bot.Action {
+	me := world.Me(m.name)
+	if me == nil || me.IsDeath() {
+		return bot.Action{} // not playing
+	}
+	iteration, _, _, _, _ := world.Stats()
+	stepTicks := int(math.Max(1, m.Step*float64(world.GameSpeed())))
+
+	// new tree (first decision or missed step)
+	wait := int(int64(m.next) - int64(iteration)) // ticks until the next move (negative: late)
+	if m.root == nil || wait < -stepTicks/2 {
+		m.root = &node{action: -1}
+		m.next = iteration
+		wait = 0
+	}
+
+	m.search(world, wait)
+	if wait > 0 {
+		return bot.Action{} // the current move is still running
+	}
+
+	// execute the best move, reuse its subtree
+	best := m.root.bestChild()
+	if best == nil {
+		m.root = nil
+		return bot.Action{}
+	}
+	if m.Verbose {
+		fmt.Printf("%d: move %d (%.3f after %d of %d visits, %d nodes)\n", iteration, best.action, best.mean(), best.visits, m.root.visits, m.root.size())
+	}
+	m.root = best
+	m.next = iteration + uint64(stepTicks)
+	if wind := m.wind(me, best.action); wind != nil {
+		return bot.Action{Wind: wind}
+	}
+	return bot.Action{} // no move
+}
+
+// search runs iterations until the budget is used, the root of the tree is wait ticks ahead of the world
+func (m *MCTS) search(world *core.World, wait int) {
+	var deadline time.Time
+	if m.Budget > 0 {
+		deadline = time.Now().Add(m.Budget)
+	}
+	limit := m.Iterations
+	if limit <= 0 && deadline.IsZero() {
+		limit = 1000 // neither budget nor iterations
+	}
+
+	// the world at the start of the next move (the root of the tree)
+	origin := world.Clone()
+	origin.SimSpeedUp = m.SimSpeedUp
+	origin.SubStepping = m.SubStepping
+	m.advance(origin, wait)
+
+	for i := 0; limit <= 0 || i < limit; i++ {
+		if !deadline.IsZero() && deadline.Before(time.Now()) {
+			break
+		}
+		m.iterate(origin)
+	}
+}
+
+// iterate is one selection, expansion, rollout and backpropagation
+func (m *MCTS) iterate(origin *core.World) {
+	w := origin.Clone() // keeps SimSpeedUp and SubStepping
+	start := m.snapshot(w)
+	actions := 1 + m.Angles*len(m.Strengths)
+	stepTicks := int(m.Step * float64(w.GameSpeed()))
+
+	// selection & expansion
+	path := []*node{m.root}
+	n := m.root
+	for depth := 0; depth < m.Depth; depth++ {
+		if n.expandable(actions, m.rnd) {
+			n = n.expand()
+		} else {
+			n = n.selectChild(m.Exploration)
+		}
+		path = append(path, n)
+		if me := w.Me(m.name); me != nil {
+			if wind := m.wind(me, n.action); wind != nil {
+				w.Move(me, wind)
+			}
+		}
+		m.advance(w, stepTicks)
+		if me := w.Me(m.name); me == nil || me.IsDeath() {
+			break // terminal
+		}
+		if n.visits == 0 {
+			break // new node: rollout
+		}
+	}
+
+	// rollout: no own moves until the end of the horizon
+	for t := (len(path) - 1) * stepTicks; t < m.Depth*stepTicks; t += stepTicks {
+		if me := w.Me(m.name); me == nil || me.IsDeath() {
+			break
+		}
+		m.advance(w, stepTicks)
+	}
+
+	// backpropagation
+	reward := m.reward(start, w)
+	for _, p := range path {
+		p.visits++
+		p.value += reward
+	}
+}
+
+// advance updates the world for ticks game ticks, the opponents act at the beginning
+func (m *MCTS) advance(w *core.World, ticks int) {
+	if ticks <= 0 {
+		return
+	}
+	if m.Policy != nil {
+		for _, c := range w.Clouds() {
+			if c.Player == "" || c.Player == m.name || c.IsDeath() {
+				continue
+			}
+			if wind := m.Policy.Act(w, c); wind != nil {
+				w.Move(w.Me(c.Player), wind)
+			}
+		}
+	}
+	speedUp := w.SimSpeedUp
+	if speedUp < 1 {
+		speedUp = 1
+	}
+	for t := 0; t < ticks; t += speedUp {
+		w.Update()
+	}
+}
+
+// wind returns the move command of an action index (nil: no move)
+func (m *MCTS) wind(me *core.Cloud, action int) *core.Velocity {
+	if action <= 0 {
+		return nil
+	}
+	action--
+	angle := float64(action/len(m.Strengths)) * 2 * math.Pi / float64(m.Angles)
+	strength := float64(me.Vapor * m.Strengths[action%len(m.Strengths)] / 100)
+	return core.NewVelocity(float32(math.Cos(angle)*strength), float32(math.Sin(angle)*strength))
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// state is the part of the world that is rewarded
+type state struct {
+	vapor   float32
+	enemies int
+}
+
+func (m *MCTS) snapshot(w *core.World) state {
+	s := state{}
+	for _, c := range w.Clouds() {
+		if c.IsDeath() || c.Player == "" {
+			continue
+		}
+		if c.Player == m.name {
+			s.vapor = c.Vapor
+		} else {
+			s.enemies++
+		}
+	}
+	return s
+}
+
+// reward is the relative vapor gain plus a bonus for destroyed enemies, -1 for the own death
+func (m *MCTS) reward(start state, w *core.World) float64 {
+	end := m.snapshot(w)
+	if end.vapor < 1 || start.vapor < 1 {
+		return -1
+	}
+	r := float64((end.vapor - start.vapor) / start.vapor)
+	r += 0.25 * float64(start.enemies-end.enemies)
+	return math.Max(-1, math.Min(1, r))
+}
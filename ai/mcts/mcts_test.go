@@ -0,0 +1,102 @@
+package mcts
+
+import (
+	"CloudWars/core"
+	"math"
+	"testing"
+)
+
+// fixedMCTS returns a reproducible MCTS (iterations instead of a time budget)
+func fixedMCTS(iterations int) *MCTS {
+	m := NewMCTS("A", "blue")
+	m.Budget = 0
+	m.Iterations = iterations
+	m.Seed(1)
+	return m
+}
+
+func TestMCTS_Food(t *testing.T) {
+	// a neutral cloud on the right
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(300, 300), 600)
+	w.AddPlayer("B", "red", core.NewPosition(900, 500), 600)
+	w.AddPlayer("", "", core.NewPosition(420, 300), 200)
+
+	a := fixedMCTS(2000).Act(w.Clone())
+	if a.Wind == nil || a.Wind.X <= 0 || math.Abs(float64(a.Wind.Y)) > math.Abs(float64(a.Wind.X)) {
+		t.Errorf("fail: %v", a.Wind)
+	}
+}
+
+func TestMCTS_Threat(t *testing.T) {
+	// a large enemy from the left
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 400)
+	b := w.AddPlayer("B", "red", core.NewPosition(380, 300), 2000)
+	b.Vel = core.NewVelocity(30, 0)
+
+	m := fixedMCTS(2000)
+	a := m.Act(w.Clone())
+	if a.Wind == nil || a.Wind.X <= 0 || m.root.mean() <= -1 {
+		t.Errorf("fail: %v %v", a.Wind, m.root.mean())
+	}
+}
+
+func TestMCTS_Reuse(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 10, 5, 200, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 600)
+	w.AddPlayer("B", "red", core.NewPosition(200, 300), 600)
+	m := fixedMCTS(200)
+
+	// first decision: a move is executed, the subtree is kept
+	m.Act(w.Clone())
+	root, visits := m.root, m.root.visits
+	if root == nil || visits == 0 || m.next != 30 {
+		t.Fatalf("fail: %v %d", root, m.next)
+	}
+
+	// during the move: the search continues on the same tree
+	for i := 0; i < 6; i++ {
+		w.Update()
+	}
+	if a := m.Act(w.Clone()); a.Wind != nil || m.root != root || m.root.visits != visits+200 {
+		t.Errorf("fail: %+v %d", a, m.root.visits)
+	}
+
+	// late (less than half a step): the move is executed on the same tree
+	for i := 0; i < 29; i++ {
+		w.Update()
+	}
+	m.Act(w.Clone())
+	if m.root.visits >= visits+400 || m.next != 35+30 {
+		t.Errorf("fail: %d %d", m.root.visits, m.next)
+	}
+	root = m.root
+
+	// missed step: new tree
+	for i := 0; i < 60; i++ {
+		w.Update()
+	}
+	m.Act(w.Clone())
+	if m.root == root || m.next != 95+30 {
+		t.Errorf("fail: %d", m.next)
+	}
+}
+
+func TestMCTS_Wind(t *testing.T) {
+	m := NewMCTS("A", "blue")
+	me := &core.Cloud{Vapor: 1000}
+	if m.wind(me, 0) != nil {
+		t.Errorf("fail: no move")
+	}
+	// first direction (angle 0), strengths 3, 10 and 25 %
+	for i, s := range []float32{30, 100, 250} {
+		if w := m.wind(me, 1+i); math.Abs(float64(w.X-s)) > 1e-3 || math.Abs(float64(w.Y)) > 1e-3 {
+			t.Errorf("fail: %v", w)
+		}
+	}
+	// last action: 315 degrees, 25 %
+	if w := m.wind(me, 8*3); math.Abs(float64(w.Strength()-250)) > 1e-3 || w.X <= 0 || w.Y >= 0 {
+		t.Errorf("fail: %v", w)
+	}
+}
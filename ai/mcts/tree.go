@@ -0,0 +1,75 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+)
+
+// node is an action sequence of the open-loop search tree.
+// The world states are not stored, every iteration simulates the sequence again on a clone of the world,
+// so the statistics of a subtree stay valid when the tree is reused for the next decision.
+type node struct {
+	action   int // index of the move command (see MCTS.wind)
+	visits   int
+	value    float64 // sum of the rewards
+	children []*node
+	untried  []int // actions that are not expanded yet (random order, created on the first expansion)
+}
+
+// expand adds the next untried action as child
+func (n *node) expand() *node {
+	a := n.untried[len(n.untried)-1]
+	n.untried = n.untried[:len(n.untried)-1]
+	child := &node{action: a}
+	n.children = append(n.children, child)
+	return child
+}
+
+// expandable is true if not all actions have a child yet
+func (n *node) expandable(actions int, rnd *rand.Rand) bool {
+	if n.untried == nil && len(n.children) == 0 {
+		n.untried = rnd.Perm(actions)
+	}
+	return len(n.untried) > 0
+}
+
+// selectChild returns the child with the best upper confidence bound (UCT)
+func (n *node) selectChild(exploration float64) *node {
+	var best *node
+	bestScore := math.Inf(-1)
+	logN := math.Log(float64(n.visits))
+	for _, c := range n.children {
+		score := c.mean() + exploration*math.Sqrt(logN/float64(c.visits))
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// bestChild returns the most visited child (robust child) or nil
+func (n *node) bestChild() *node {
+	var best *node
+	for _, c := range n.children {
+		if best == nil || c.visits > best.visits || (c.visits == best.visits && c.mean() > best.mean()) {
+			best = c
+		}
+	}
+	return best
+}
+
+func (n *node) mean() float64 {
+	if n.visits == 0 {
+		return 0
+	}
+	return n.value / float64(n.visits)
+}
+
+// size returns the number of nodes of the subtree
+func (n *node) size() int {
+	s := 1
+	for _, c := range n.children {
+		s += c.size()
+	}
+	return s
+}
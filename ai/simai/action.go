@@ -11,9 +11,7 @@ type actions []*action
 func (aa actions) ResetResults() {
 	for _, a := range aa {
 		a.EvaluationPoints = 0
-		a.ShortTerm = actionResult{}
-		a.MidTerm = actionResult{}
-		a.LongTerm = actionResult{}
+		a.Results = nil
 	}
 }
 
@@ -25,14 +23,13 @@ type action struct {
 	Strength float32
 	// results
 	EvaluationPoints float64
-	ShortTerm        actionResult
-	MidTerm          actionResult
-	LongTerm         actionResult
+	Results          []Result // one result per horizon of the rollout
 }
 
 //--------------------------------------------------------------------------------------------------------------------//
 
-type actionResult struct {
+// Result is the state of the simulated future at the end of a horizon (see Rollout).
+type Result struct {
 	StartIteration uint64
 	EndIteration   uint64
 	UsedWind       float32
@@ -44,27 +41,56 @@ type actionResult struct {
 
 //--------------------------------------------------------------------------------------------------------------------//
 
-func actionsSplitList(cpus, angleSteps int, strengths []float32) []actions {
+// Generator creates the move commands that are simulated for a decision.
+type Generator interface {
+	Actions(world *core.World, me *core.Cloud) []*core.Velocity
+}
+
+// Seeder is an optional interface of a Generator.
+// A seeded generator draws its random numbers from its own source and forgets its state (see SimAI Reset).
+type Seeder interface {
+	Seed(seed int64)
+}
+
+// Grid generates no move and moves in all directions (AngleStep degrees) with all strengths.
+// The order of the directions is shuffled per strength, so a decision under time pressure
+// (see SimAI Budget) simulates a random subset.
+type Grid struct {
+	AngleStep float32   // DEFAULT: 4
+	Strengths []float32 // DEFAULT: 10, 50, 100, 200, 300
+	Shuffle   bool      // DEFAULT: true
+
+	rnd *rand.Rand // random source (nil: math/rand, see Seed)
+}
 
-	// init basic list
-	all := make(actions, 0)
+func (g *Grid) Seed(seed int64) {
+	g.rnd = rand.New(rand.NewSource(seed))
+}
 
+func (g *Grid) Actions(world *core.World, me *core.Cloud) []*core.Velocity {
 	// add first action : NOTHING (wind = 0)
-	all = append(all, &action{Wind: core.NewVelocity(0, 0), Strength: 0})
+	all := []*core.Velocity{core.NewVelocity(0, 0)}
 
 	// add random moves to basis list
-	for _, strength := range strengths {
-		aa := make(actions, 0)
-		for i := 0; i < 360; i += angleSteps {
-			a := &action{
-				Wind:     core.NewVelocityByAngle(float32(i), strength),
-				Strength: strength,
-			}
-			aa = append(aa, a)
+	for _, strength := range g.Strengths {
+		aa := make([]*core.Velocity, 0)
+		for i := float32(0); i < 360; i += g.AngleStep {
+			aa = append(aa, core.NewVelocityByAngle(i, strength))
+		}
+		if g.Shuffle && g.rnd != nil {
+			g.rnd.Shuffle(len(aa), func(i, j int) { aa[i], aa[j] = aa[j], aa[i] })
+		} else if g.Shuffle {
+			rand.Shuffle(len(aa), func(i, j int) { aa[i], aa[j] = aa[j], aa[i] })
 		}
-		rand.Shuffle(len(aa), func(i, j int) { aa[i], aa[j] = aa[j], aa[i] })
 		all = append(all, aa...)
 	}
+	return all
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// actionsSplitList distributes the move commands on the cpus
+func actionsSplitList(cpus int, winds []*core.Velocity) []actions {
 
 	// prepare return list
 	ret := make([]actions, cpus)
@@ -74,8 +100,8 @@ func actionsSplitList(cpus, angleSteps int, strengths []float32) []actions {
 
 	// split basic list
 	i := 0
-	for _, a := range all {
-		ret[i] = append(ret[i], a)
+	for _, w := range winds {
+		ret[i] = append(ret[i], &action{Wind: w, Strength: w.Strength()})
 		i++
 		if i >= cpus {
 			i = 0
@@ -0,0 +1,187 @@
+package simai
+
+import (
+	"CloudWars/core"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Feedback is an optional interface of a Generator.
+// After each decision, the generator gets the best move and the measured simulations per second.
+type Feedback interface {
+	Feedback(best *core.Velocity, rate float64, budget time.Duration)
+}
+
+// Adaptive generates the move commands from the situation instead of a fixed grid:
+// intercept courses to the nearest smaller clouds, escapes from larger clouds, refinements of the previous best move
+// and random directions for the rest. The number of move commands scales with the simulations per second
+// that fit into the thinking time of the SimAI (see Feedback), without a time budget Max are generated.
+// The escapes are kept first (up to half of the move commands), so the SimAI can flee under load.
+type Adaptive struct {
+	Strengths []float32 // DEFAULT: 10, 50, 100, 200, 300
+	Range     float32   // distance of the targets and threats (DEFAULT: 400)
+	Targets   int       // nearest smaller clouds (DEFAULT: 8)
+	Threats   int       // nearest larger clouds (DEFAULT: 4)
+	Spread    float32   // degrees around aimed directions (DEFAULT: 8)
+	Min       int       // DEFAULT: 32
+	Max       int       // DEFAULT: 451 (like the grid)
+
+	n    int            // move commands of the next decision
+	last *core.Velocity // best move of the last decision
+	rnd  *rand.Rand     // random source (nil: math/rand, see Seed)
+}
+
+func (a *Adaptive) Seed(seed int64) {
+	a.n = 0
+	a.last = nil
+	a.rnd = rand.New(rand.NewSource(seed))
+}
+
+func (a *Adaptive) Feedback(best *core.Velocity, rate float64, budget time.Duration) {
+	a.last = best
+	if rate <= 0 || budget <= 0 {
+		a.n = a.Max // simulate all
+		return
+	}
+	a.n = int(rate * budget.Seconds() * 0.9) // 10 % reserve
+	if a.n < a.Min {
+		a.n = a.Min
+	} else if a.n > a.Max {
+		a.n = a.Max
+	}
+}
+
+func (a *Adaptive) Actions(world *core.World, me *core.Cloud) []*core.Velocity {
+	n := a.n
+	if n <= 0 {
+		n = a.Max // first decision
+	}
+
+	// add first action : NOTHING (wind = 0)
+	all := []*core.Velocity{core.NewVelocity(0, 0)}
+	var refine, aimed, escapes []*core.Velocity
+	add := func(list *[]*core.Velocity, angle float64, strength float32) {
+		if strength >= 1 && strength <= me.Vapor/2 {
+			*list = append(*list, byRadians(angle, strength))
+		}
+	}
+	spread := float64(a.Spread) * math.Pi / 180
+
+	// refine the last best move
+	if a.last != nil && a.last.Strength() >= 1 {
+		angle := math.Atan2(float64(a.last.Y), float64(a.last.X))
+		for _, f := range []float32{1, 0.5, 1.5} {
+			for _, d := range []float64{0, -spread, spread} {
+				add(&refine, angle+d, a.last.Strength()*f)
+			}
+		}
+	}
+
+	// intercept smaller clouds, escape from larger clouds
+	targets, threats := a.neighbours(world, me)
+	for _, t := range targets {
+		for _, s := range a.Strengths {
+			angle := intercept(me, t, s)
+			add(&aimed, angle, s)
+			add(&aimed, angle-spread, s)
+			add(&aimed, angle+spread, s)
+		}
+	}
+	for _, t := range threats {
+		away := math.Atan2(float64(me.Pos.Y-t.Pos.Y), float64(me.Pos.X-t.Pos.X))
+		for _, s := range a.Strengths {
+			for _, d := range []float64{0, -math.Pi / 4, math.Pi / 4, -math.Pi / 2, math.Pi / 2} {
+				add(&escapes, away+d, s)
+			}
+		}
+	}
+
+	// the escapes are not truncated first: up to half of the move commands (nearest threat first), then the
+	// refinements, the intercepts and the rest of the escapes
+	quota := len(escapes)
+	if quota > n/2 {
+		quota = n / 2
+	}
+	all = append(all, escapes[:quota]...)
+	all = append(all, refine...)
+	all = append(all, aimed...)
+	all = append(all, escapes[quota:]...)
+
+	// random directions for the rest
+	float64n, intn := rand.Float64, rand.Intn
+	if a.rnd != nil {
+		float64n, intn = a.rnd.Float64, a.rnd.Intn
+	}
+	for i := 0; len(all) < n && i < 4*n; i++ {
+		add(&all, float64n()*2*math.Pi, a.Strengths[intn(len(a.Strengths))])
+	}
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
+
+// neighbours returns the nearest smaller and larger clouds within the range
+func (a *Adaptive) neighbours(world *core.World, me *core.Cloud) (targets, threats []*core.Cloud) {
+	type near struct {
+		c *core.Cloud
+		d float32
+	}
+	var smaller, larger []near
+	for _, c := range world.Clouds() {
+		if c.UID == me.UID || c.IsDeath() {
+			continue
+		}
+		d := float32(math.Hypot(float64(c.Pos.X-me.Pos.X), float64(c.Pos.Y-me.Pos.Y))) - c.Radius() - me.Radius()
+		if d > a.Range {
+			continue
+		}
+		if c.Vapor < me.Vapor {
+			smaller = append(smaller, near{c, d})
+		} else {
+			larger = append(larger, near{c, d})
+		}
+	}
+	sort.Slice(smaller, func(i, j int) bool { return smaller[i].d < smaller[j].d })
+	sort.Slice(larger, func(i, j int) bool { return larger[i].d < larger[j].d })
+	for i := 0; i < len(smaller) && i < a.Targets; i++ {
+		targets = append(targets, smaller[i].c)
+	}
+	for i := 0; i < len(larger) && i < a.Threats; i++ {
+		threats = append(threats, larger[i].c)
+	}
+	return
+}
+
+// intercept returns the direction of the wind (radians) so that the relative velocity to the target
+// points at the target after the move. A move adds wind * 5 / radius to the velocity.
+// If the wind is too weak, the direction cancels as much of the lateral velocity as possible.
+func intercept(me, target *core.Cloud, strength float32) float64 {
+	dx, dy := float64(target.Pos.X-me.Pos.X), float64(target.Pos.Y-me.Pos.Y)
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return 0
+	}
+	dx, dy = dx/l, dy/l
+
+	// relative velocity: parallel and lateral part
+	rx, ry := float64(me.Vel.X-target.Vel.X), float64(me.Vel.Y-target.Vel.Y)
+	p := rx*dx + ry*dy
+	lx, ly := rx-p*dx, ry-p*dy
+	lateral := math.Hypot(lx, ly)
+
+	b := float64(strength) * 5 / float64(me.Radius())
+	if lateral >= b {
+		return math.Atan2(-ly, -lx) // too weak
+	}
+	// e = -lateral/b + sqrt(1 - (|lateral|/b)^2) * d
+	along := math.Sqrt(1 - (lateral/b)*(lateral/b))
+	return math.Atan2(-ly/b+along*dy, -lx/b+along*dx)
+}
+
+// byRadians creates a wind in the direction of the angle (0: +x)
+func byRadians(angle float64, strength float32) *core.Velocity {
+	return core.NewVelocity(float32(math.Cos(angle))*strength, float32(math.Sin(angle))*strength)
+}
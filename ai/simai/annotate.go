@@ -0,0 +1,79 @@
+package simai
+
+import (
+	"CloudWars/core"
+	"CloudWars/debug"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// annotate publishes the intent of a decision (see SimAI Debug):
+// the top candidates of the evaluation, the predicted path of the chosen moves, the target and the threats.
+func (s *SimAI) annotate(world *core.World, actionsList []actions, seq *sequence) {
+	me := world.Me(s.name)
+	a := &debug.Annotation{Text: fmt.Sprintf("%.0f points", seq.points)}
+	if len(seq.steps) > 1 {
+		a.Text += fmt.Sprintf(", %d moves", len(seq.steps))
+	}
+
+	// top candidates
+	var all []*action
+	for _, aa := range actionsList {
+		for _, c := range aa {
+			if c.Results != nil {
+				all = append(all, c)
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].EvaluationPoints > all[j].EvaluationPoints })
+	for i := 0; i < len(all) && i < 5; i++ {
+		a.Candidates = append(a.Candidates, debug.Candidate{X: all[i].Wind.X, Y: all[i].Wind.Y, Score: all[i].EvaluationPoints})
+	}
+
+	// predicted path (2 seconds) and the first smaller cloud on the way
+	w := world.Clone()
+	c := w.Me(s.name)
+	path := debug.Path{Points: []debug.Point{{X: c.Pos.X, Y: c.Pos.Y}}}
+	dt := math.Max(1, float64(w.SimSpeedUp)) / float64(w.GameSpeed()) // one update
+	var t float64
+	for _, st := range seq.steps {
+		for ; t < st.At-dt/2; t += dt {
+			pathStep(w, c, &path, a)
+		}
+		w.Move(c, st.Wind)
+	}
+	for ; t < 2; t += dt {
+		pathStep(w, c, &path, a)
+	}
+	a.Paths = []debug.Path{path}
+
+	// threats: larger clouds nearby
+	for _, o := range world.Clouds() {
+		if o.UID != me.UID && !o.IsDeath() && o.Vapor > me.Vapor && distance(me, o) < 300 {
+			a.Threats = append(a.Threats, debug.Circle{X: o.Pos.X, Y: o.Pos.Y, Radius: o.Radius()})
+		}
+	}
+
+	s.Debug(a)
+}
+
+// pathStep updates the world once and adds the position to the path
+func pathStep(w *core.World, me *core.Cloud, path *debug.Path, a *debug.Annotation) {
+	w.Update()
+	path.Points = append(path.Points, debug.Point{X: me.Pos.X, Y: me.Pos.Y})
+	if a.Target != "" || me.IsDeath() {
+		return
+	}
+	for _, o := range w.Clouds() {
+		if o.UID != me.UID && !o.IsDeath() && o.Vapor < me.Vapor && distance(me, o) < 1 {
+			a.Target = o.UID // touched (absorbed until the edges meet)
+			return
+		}
+	}
+}
+
+// distance between the edges of two clouds (negative: overlap)
+func distance(a, b *core.Cloud) float32 {
+	return float32(math.Hypot(float64(b.Pos.X-a.Pos.X), float64(b.Pos.Y-a.Pos.Y))) - a.Radius() - b.Radius()
+}
@@ -0,0 +1,197 @@
+package simai
+
+import (
+	"CloudWars/bot"
+	"CloudWars/core"
+	"CloudWars/debug"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// SimAI is the simulation AI as bot (see bot.Bot).
+// For each decision, the AI simulates the future of all move commands of the generator (rollout)
+// and chooses the move with the best evaluation.
+// With Depth > 1, the best moves are extended to sequences of moves (see sequence).
+type SimAI struct {
+	name  string
+	color string
+	cpus  int
+
+	Generator   Generator                 // move commands to simulate (DEFAULT: Grid)
+	Rollout     Rollout                   // simulation of the future (DEFAULT: Horizons)
+	Evaluator   Evaluator                 // scoring of the results (DEFAULT: Weights)
+	Opponents   Opponents                 // prediction of the opponent moves (DEFAULT: nil = passive)
+	SimSpeedUp  int                       // larger simulation steps (DEFAULT: 10)
+	SubStepping bool                      // no tunnelling in the simulations, but SimSpeedUp times slower (DEFAULT: false)
+	Budget      time.Duration             // max thinking time per decision (DEFAULT: 0 = simulate all actions)
+	Lookahead   time.Duration             // game time between the world snapshot and the move (DEFAULT: 0)
+	Verbose     bool                      // log the decisions
+	Debug       func(a *debug.Annotation) // publishes the intent of each decision (DEFAULT: nil = off)
+
+	Depth   int     // moves per sequence (DEFAULT: 1 = single move)
+	Delay   float64 // seconds between two moves of a sequence (DEFAULT: 0.3)
+	Beam    int     // best sequences that are extended per depth (DEFAULT: 8)
+	Samples int     // follow-up moves per sequence (DEFAULT: 24)
+
+	rate     float64    // measured simulations per second
+	sequence *sequence  // rest of the last plan (warm start)
+	planned  uint64     // iteration of the last plan
+	rnd      *rand.Rand // random source of the sequences (nil: math/rand, see Reset)
+}
+
+// NewSimAI creates a SimAI for a player with the default strategies.
+func NewSimAI(name, color string) *SimAI {
+	s, _ := NewSimAIConfig(name, color, Config{}) // the defaults are valid
+	return s
+}
+
+// NewSimAIConfig creates a SimAI for a player with the strategies of a config.
+func NewSimAIConfig(name, color string, c Config) (*SimAI, error) {
+	g, r, e, o, err := c.strategies()
+	if err != nil {
+		return nil, err
+	}
+	s := &SimAI{
+		name:        name,
+		color:       color,
+		cpus:        runtime.NumCPU(),
+		Generator:   g,
+		Rollout:     r,
+		Evaluator:   e,
+		Opponents:   o,
+		SimSpeedUp:  10,
+		Depth:       c.Depth,
+		Delay:       c.Delay,
+		Beam:        c.Beam,
+		Samples:     c.Samples,
+		SubStepping: c.SubStepping,
+	}
+	if s.Depth <= 0 {
+		s.Depth = 1
+	}
+	if s.Delay <= 0 {
+		s.Delay = 0.3
+	}
+	if s.Beam <= 0 {
+		s.Beam = 8
+	}
+	if s.Samples <= 0 {
+		s.Samples = 24
+	}
+	return s, nil
+}
+
+func (s *SimAI) Name() string {
+	return s.name
+}
+
+func (s *SimAI) Color() string {
+	return s.color
+}
+
+// Reset forgets the last game (measured rate, warm start) and seeds the random numbers of the SimAI and
+// its generator (see Seeder). Without a Budget, the same seed and the same game result in the same decisions.
+func (s *SimAI) Reset(seed int64) {
+	s.rate = 0
+	s.sequence = nil
+	s.planned = 0
+	s.rnd = rand.New(rand.NewSource(seed))
+	if g, ok := s.Generator.(Seeder); ok {
+		g.Seed(seed)
+	}
+}
+
+// Act simulates the world after the lookahead and returns the best move command.
+func (s *SimAI) Act(world *core.World) bot.Action {
+	if me := world.Me(s.name); me == nil || me.IsDeath() {
+		return bot.Action{} // not playing
+	}
+	ticks := s.Lookahead.Seconds() * float64(world.GameSpeed())
+	for i := 0; i < int(ticks); i++ {
+		world.Update()
+	}
+	if wind := s.decide(world); wind.Strength() > 0 {
+		return bot.Action{Wind: wind}
+	}
+	return bot.Action{} // no move
+}
+
+// decide plans the moves on the world and returns the move command for now (zero: no move).
+func (s *SimAI) decide(world *core.World) *core.Velocity {
+	var deadline time.Time
+	if s.Budget > 0 {
+		deadline = time.Now().Add(s.Budget)
+	}
+	iteration, _, _, _, _ := world.Stats()
+
+	// single moves
+	best, actionsList := s.plan(world, deadline)
+	if s.Depth <= 1 && s.sequence == nil {
+		if s.Debug != nil {
+			s.annotate(world, actionsList, &sequence{steps: []step{{Wind: best.Wind}}, points: best.EvaluationPoints})
+		}
+		return best.Wind
+	}
+
+	// move sequences (the rest of the last plan is a candidate)
+	elapsed := float64(iteration-s.planned) / float64(world.GameSpeed())
+	seq := s.search(world, actionsList, s.sequence.shift(elapsed), deadline)
+
+	// execute the first move now or wait for it
+	s.planned = iteration
+	s.sequence = seq.next()
+	if s.Verbose && len(seq.steps) > 1 {
+		logSequence(seq)
+	}
+	if s.Debug != nil {
+		s.annotate(world, actionsList, seq)
+	}
+	if seq.steps[0].At > 0 {
+		return core.NewVelocity(0, 0) // wait
+	}
+	return seq.steps[0].Wind
+}
+
+// plan simulates all actions on the world and returns the best one (and all simulated actions).
+// The world is changed (see SimSpeedUp and Opponents).
+func (s *SimAI) plan(world *core.World, deadline time.Time) (*action, []actions) {
+	world.SimSpeedUp = s.SimSpeedUp
+	world.SubStepping = s.SubStepping
+	predict(world, s.name, s.Opponents)
+	me := world.Me(s.name)
+
+	// prepare actions (list of move commands)
+	actions := actionsSplitList(s.cpus, s.Generator.Actions(world, me))
+
+	// start go simulations (a single cpu simulates on the calling goroutine)
+	start := time.Now()
+	wg := new(sync.WaitGroup)
+	wg.Add(s.cpus)
+	if s.cpus == 1 {
+		simulate(wg, deadline, world, s.name, actions[0], s.Rollout)
+	} else {
+		for i := 0; i < s.cpus; i++ {
+			go simulate(wg, deadline, world, s.name, actions[i], s.Rollout)
+		}
+	}
+	wg.Wait() // wait for simulations
+
+	// simulations per second
+	done, all := simulated(actions)
+	if sec := time.Since(start).Seconds(); sec > 0 {
+		s.rate = float64(done) / sec
+	}
+	if !deadline.IsZero() && done < all {
+		fmt.Printf("ALERT: Not enough computing power!  %.0f %%  (%.0f simulations/s)\n", float64(done)/float64(all)*100, s.rate)
+	}
+
+	// find best result
+	best := evaluation(actions, me, s.Evaluator, s.Verbose)
+	if f, ok := s.Generator.(Feedback); ok {
+		f.Feedback(best.Wind, s.rate, s.Budget)
+	}
+	return best, actions
+}
@@ -0,0 +1,133 @@
+package simai
+
+import (
+	"CloudWars/bot"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config selects the strategies of a SimAI (json file).
+// Own strategies are added to Generators, Rollouts, Evaluators or OpponentModels before the config is used.
+type Config struct {
+	Generator string `json:",omitempty"` // name in Generators (DEFAULT: grid)
+	Rollout   string `json:",omitempty"` // name in Rollouts (DEFAULT: horizons)
+	Evaluator string `json:",omitempty"` // name in Evaluators (DEFAULT: weights)
+	Opponents string `json:",omitempty"` // name in OpponentModels (DEFAULT: passive)
+
+	// parameters of the built-in strategies
+	AngleStep float32   `json:",omitempty"` // grid: degrees between two directions (DEFAULT: 4)
+	Strengths []float32 `json:",omitempty"` // grid: wind strengths (DEFAULT: 10, 50, 100, 200, 300)
+	Horizons  []float64 `json:",omitempty"` // horizons: seconds (DEFAULT: 0.6, 1, 2)
+	Weights   *Weights  `json:",omitempty"` // weights (DEFAULT: DefaultWeights)
+
+	// move sequences (see SimAI)
+	Depth   int     `json:",omitempty"` // moves per sequence (DEFAULT: 1 = single move)
+	Delay   float64 `json:",omitempty"` // seconds between two moves (DEFAULT: 0.3)
+	Beam    int     `json:",omitempty"` // best sequences that are extended per depth (DEFAULT: 8)
+	Samples int     `json:",omitempty"` // follow-up moves per sequence (DEFAULT: 24)
+
+	SubStepping bool `json:",omitempty"` // simulate every game tick (see SimAI SubStepping)
+}
+
+// Generators creates the action generators by name.
+var Generators = map[string]func(c Config) Generator{
+	"grid": func(c Config) Generator {
+		return &Grid{AngleStep: c.AngleStep, Strengths: c.Strengths, Shuffle: true}
+	},
+	"adaptive": func(c Config) Generator {
+		return &Adaptive{Strengths: c.Strengths, Range: 400, Targets: 8, Threats: 4, Spread: 8, Min: 32, Max: 451}
+	},
+}
+
+// Rollouts creates the rollout policies by name.
+var Rollouts = map[string]func(c Config) Rollout{
+	"horizons": func(c Config) Rollout {
+		return &Horizons{Seconds: c.Horizons}
+	},
+}
+
+// Evaluators creates the evaluations by name.
+var Evaluators = map[string]func(c Config) Evaluator{
+	"weights": func(c Config) Evaluator {
+		w := *c.Weights
+		return &w
+	},
+}
+
+// OpponentModels creates the opponent models by name.
+var OpponentModels = map[string]func(c Config) Opponents{
+	"passive": func(c Config) Opponents {
+		return nil // the opponents drift
+	},
+	"greedy": func(c Config) Opponents {
+		return &PolicyModel{Policy: bot.NewGreedy()}
+	},
+	"observed": func(c Config) Opponents {
+		return &Observed{Memory: 1}
+	},
+	"simai": func(c Config) Opponents {
+		return &SelfPlay{config: Config{AngleStep: 45, Strengths: []float32{50, 200}}} // valid (see NewSelfPlay)
+	},
+}
+
+// LoadConfig reads a SimAI config file.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// strategies returns the strategies of the config
+func (c Config) strategies() (Generator, Rollout, Evaluator, Opponents, error) {
+	// defaults
+	if c.Generator == "" {
+		c.Generator = "grid"
+	}
+	if c.Rollout == "" {
+		c.Rollout = "horizons"
+	}
+	if c.Evaluator == "" {
+		c.Evaluator = "weights"
+	}
+	if c.Opponents == "" {
+		c.Opponents = "passive"
+	}
+	if c.AngleStep <= 0 {
+		c.AngleStep = 360 / 90
+	}
+	if len(c.Strengths) == 0 {
+		c.Strengths = []float32{10, 50, 100, 200, 300}
+	}
+	if len(c.Horizons) == 0 {
+		c.Horizons = []float64{0.6, 1.0, 2.0}
+	}
+	if c.Weights == nil {
+		c.Weights = &DefaultWeights
+	}
+
+	// strategies
+	g, ok := Generators[c.Generator]
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("unknown generator '%s'", c.Generator)
+	}
+	r, ok := Rollouts[c.Rollout]
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("unknown rollout '%s'", c.Rollout)
+	}
+	e, ok := Evaluators[c.Evaluator]
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("unknown evaluator '%s'", c.Evaluator)
+	}
+	o, ok := OpponentModels[c.Opponents]
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("unknown opponent model '%s'", c.Opponents)
+	}
+	return g(c), r(c), e(c), o(c), nil
+}
@@ -6,51 +6,94 @@ import (
 	"math"
 )
 
-func evaluation(actionsList []actions, me *core.Cloud) *action {
+// Evaluator scores the results of a move command (higher is better).
+type Evaluator interface {
+	Evaluate(me *core.Cloud, wind *core.Velocity, results []Result) float64
+}
+
+// Weights is the default evaluation of the short, mid and long term results (see Horizons).
+type Weights struct {
+	ShortVapor float64 // weight of the vapor increase in percent
+	MidVapor   float64
+	LongVapor  float64
+	Wind       float64 // weight of the vapor used for the move in percent
+	Death      float64 // own short or mid term death
+	LongDeath  float64 // own long term death
+	ShortKill  float64 // a destroyed enemy
+	MidKill    float64
+	LongKill   float64
+}
+
+// DefaultWeights are the weights of the classic SimAI.
+var DefaultWeights = Weights{
+	ShortVapor: 1,
+	MidVapor:   0.75,
+	LongVapor:  0.3,
+	Wind:       1,
+	Death:      -1000,
+	LongDeath:  -200,
+	ShortKill:  50,
+	MidKill:    40,
+	LongKill:   25,
+}
+
+func (ws *Weights) Evaluate(me *core.Cloud, wind *core.Velocity, results []Result) float64 {
+	if len(results) < 3 {
+		return math.Inf(-1) // 3 horizons needed
+	}
+	short, mid, long := results[0], results[1], results[2]
+	var points float64
+
+	// Calculates the percentage of vapor increase.
+	// Depending on the period, the additional vapor is weighted less.
+	// maxPerIncrease = |-30|5|30|61|204|
+	sTPerIncrease := float64(short.GainVapor/me.Vapor*100) * ws.ShortVapor
+	mTPerIncrease := float64(mid.GainVapor/me.Vapor*100) * ws.MidVapor   // correction factor
+	lTPerIncrease := float64(long.GainVapor/me.Vapor*100) * ws.LongVapor // correction factor
+	points += math.Max(math.Max(sTPerIncrease, mTPerIncrease), lTPerIncrease)
+
+	// the percentage output is deducted from the points
+	// perWindUsage = |0|2|9|24|100|
+	perWindUsage := float64(wind.Strength()) / float64(me.Vapor) * 100
+	points -= perWindUsage * ws.Wind
+
+	// the own short or mid term death, the own long term death
+	if !short.Alive || !mid.Alive {
+		points += ws.Death
+	} else if !long.Alive {
+		points += ws.LongDeath
+	}
+
+	// a destroyed enemy
+	if short.DeadEnemies > 0 {
+		points += ws.ShortKill
+	} else if mid.DeadEnemies > 0 {
+		points += ws.MidKill
+	} else if long.DeadEnemies > 0 {
+		points += ws.LongKill
+	}
+
+	return points
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// evaluation returns the best simulated action (actions without results are ignored)
+func evaluation(actionsList []actions, me *core.Cloud, evaluator Evaluator, verbose bool) *action {
 
 	var best = &action{
 		Wind:             &core.Velocity{},
 		Strength:         0,
-		EvaluationPoints: -10000000,
-		ShortTerm:        actionResult{GainVapor: -10000000, GainSpeed: -10000000},
-		MidTerm:          actionResult{GainVapor: -10000000, GainSpeed: -10000000},
-		LongTerm:         actionResult{GainVapor: -10000000, GainSpeed: -10000000},
+		EvaluationPoints: math.Inf(-1),
 	}
 
 	// preselection
 	for _, aa := range actionsList {
 		for _, a := range aa { //--------------------------
-
-			// Calculates the percentage of vapor increase.
-			// Depending on the period, the additional vapor is weighted less.
-			// maxPerIncrease = |-30|5|30|61|204|
-			sTPerIncrease := a.ShortTerm.GainVapor / me.Vapor * 100
-			mTPerIncrease := a.MidTerm.GainVapor / me.Vapor * 100 * 0.75 // correction factor
-			lTPerIncrease := a.LongTerm.GainVapor / me.Vapor * 100 * 0.3 // correction factor
-			maxPerIncrease := math.Max(math.Max(float64(sTPerIncrease), float64(mTPerIncrease)), float64(lTPerIncrease))
-			a.EvaluationPoints += maxPerIncrease
-
-			// the percentage output is deducted from the points
-			// perWindUsage = |0|2|9|24|100|
-			perWindUsage := float64(a.Strength) / float64(me.Vapor) * 100
-			a.EvaluationPoints -= perWindUsage
-
-			// -1000 der eigene kurz oder mittelfristige tod
-			// -500 der eigene langfristige tod
-			if !a.ShortTerm.Alive || !a.MidTerm.Alive {
-				a.EvaluationPoints -= 1000
-			} else if !a.LongTerm.Alive {
-				a.EvaluationPoints -= 200
-			}
-
-			// +500 ein vernichteter feind
-			if a.ShortTerm.DeadEnemies > 0 {
-				a.EvaluationPoints += 50
-			} else if a.MidTerm.DeadEnemies > 0 {
-				a.EvaluationPoints += 40
-			} else if a.LongTerm.DeadEnemies > 0 {
-				a.EvaluationPoints += 25
+			if a.Results == nil {
+				continue // not simulated (deadline)
 			}
+			a.EvaluationPoints = evaluator.Evaluate(me, a.Wind, a.Results)
 
 			// find best
 			if a.EvaluationPoints > best.EvaluationPoints {
@@ -60,15 +103,17 @@ func evaluation(actionsList []actions, me *core.Cloud) *action {
 	}
 
 	// log action
-	if best.Strength > 0 {
+	if verbose && best.Strength > 0 {
 		fmt.Printf("%.0f Wind  for  %.0f Points    ", best.Strength, best.EvaluationPoints)
 		if best.EvaluationPoints < 0 {
 			fmt.Printf("escape!")
 		}
-		if best.ShortTerm.DeadEnemies > 0 || best.MidTerm.DeadEnemies > 0 {
-			fmt.Printf("KILL enemies!")
-		} else if best.LongTerm.DeadEnemies > 0 {
-			fmt.Printf("hunt enemies!")
+		if len(best.Results) >= 3 {
+			if best.Results[0].DeadEnemies > 0 || best.Results[1].DeadEnemies > 0 {
+				fmt.Printf("KILL enemies!")
+			} else if best.Results[2].DeadEnemies > 0 {
+				fmt.Printf("hunt enemies!")
+			}
 		}
 		fmt.Printf("\n")
 	}
@@ -2,11 +2,11 @@ package simai
 
 import (
 	"CloudWars/core"
+	"CloudWars/debug"
 	"CloudWars/remote"
+	"fmt"
 	"log"
-	"runtime"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -14,52 +14,56 @@ import (
 // the remote.TcpClient and controls a player cloud.
 // The AI calculates the future (8 sec) of 64 random
 // movement commands every 100 ms and executes the best option.
-func RunSimAI(host, port, name, color string) {
+// The config selects the strategies (see Config, empty: classic SimAI).
+// With annotate the AI publishes its debug annotations for the GUI overlay (see SimAI.Debug).
+func RunSimAI(host, port, name, color string, c Config, annotate bool) {
 
 	// CONFIG ------------------------------------------
-	var cpus = runtime.NumCPU()
-	var angleSteps = 360 / 90
-	var strengths = []float32{10, 50, 100, 200, 300}
 	var simSpeedUp = 10
 	var simInterval = 250 * time.Millisecond
 	//--------------------------------------------------
 
-	// prepare actions (list of move commands)
-	actions := actionsSplitList(cpus, angleSteps, strengths)
+	// prepare ai (strategies)
+	ai, err := NewSimAIConfig(name, color, c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ai.SimSpeedUp = simSpeedUp
+	ai.Budget = simInterval
+	ai.Verbose = true
 
 	// connect to server and start game
 	tcpClient := startGame(host, port, name, color)
+	if annotate {
+		ai.Debug = func(a *debug.Annotation) { tcpClient.Debug(a) } // debug overlay of the GUI
+	}
+	pred := &prediction{name: name}
+
+	// lock-step server: the world waits for the move (no latency)
+	lockstep := strings.HasPrefix(tcpClient.Step(), "ok")
+	lookahead := simInterval
+	if lockstep {
+		lookahead = 0
+	}
 
 	// ai loop
 	for { //------------------------------------------------------------------------------------------------------------
 		deadline := time.Now().Add(simInterval)
 
 		// get new world status & calc future
-		originWorld := loadStatus(tcpClient, simSpeedUp, simInterval)
+		originWorld := loadStatus(tcpClient, simSpeedUp, lookahead, pred)
 		me := originWorld.Me(name)
 
-		// start go simulations
-		{ //---------------------------------
-			wg := new(sync.WaitGroup)
-			wg.Add(cpus) // Add a count of two, one for each goroutine.
-
-			// go routines
-			for i := 0; i < cpus; i++ {
-				aa := actions[i]
-				aa.ResetResults() // clear old results
-				go simulate(i, wg, deadline, originWorld, name, aa)
-			}
-
-			// wait
-			wg.Wait() // wait for simulations
-			for !deadline.Before(time.Now()) {
-				time.Sleep(100 * time.Microsecond) // wait for timeout
-			}
-		} //---------------------------------
-
-		// find best result
-		a := evaluation(actions, me)
-		tcpClient.Move(a.Wind)
+		// start go simulations & find best result
+		wind := ai.decide(originWorld)
+		for !lockstep && !deadline.Before(time.Now()) {
+			time.Sleep(100 * time.Microsecond) // wait for timeout
+		}
+		tcpClient.Move(wind)
+		pred.move(wind)
+		if lockstep {
+			tcpClient.Step() // wait for the next step
+		}
 
 		// OPTIONAL: exit loop
 		if me.IsDeath() {
@@ -69,6 +73,7 @@ func RunSimAI(host, port, name, color string) {
 
 	// exit loop
 	println("END", name)
+	fmt.Println(pred)
 	tcpClient.Kill()
 	tcpClient.Close()
 }
@@ -90,18 +95,21 @@ func startGame(host, port, name, color string) *remote.TcpClient {
 	return t
 }
 
-func loadStatus(tcpClient *remote.TcpClient, simSpeedUp int, simInterval time.Duration) *core.World {
-	// get new world
-	json := tcpClient.List()
-	w := &core.World{}
-	w.FromJson(json)
+func loadStatus(tcpClient *remote.TcpClient, simSpeedUp int, simInterval time.Duration, pred *prediction) *core.World {
+	// get new world (and compare it with the last prediction)
+	w := pred.verify(tcpClient)
+	server := w.Clone()
 
 	// simulate future
 	ticks := simInterval.Seconds() * float64(w.GameSpeed())
-	for i := float64(0); i <= ticks; i++ {
+	for i := float64(0); simInterval > 0 && i <= ticks; i++ {
 		w.Update()
 	}
 
+	// this is the prediction for the next loop (see prediction.move)
+	iteration, _, _, _, _ := w.Stats()
+	pred.start(server, iteration)
+
 	// set sim speed
 	w.SimSpeedUp = simSpeedUp
 
@@ -0,0 +1,150 @@
+package simai
+
+import (
+	"CloudWars/bot"
+	"CloudWars/core"
+	"math"
+	"time"
+)
+
+// Opponents predicts the move commands of the opponents for a decision (player name -> wind).
+// The predicted moves are executed on the world before the rollouts, so all actions are simulated against them.
+type Opponents interface {
+	Predict(world *core.World, me string) map[string]*core.Velocity
+}
+
+// predict executes the predicted moves of the opponents on the world
+func predict(world *core.World, me string, o Opponents) {
+	if o == nil {
+		return
+	}
+	for player, wind := range o.Predict(world, me) {
+		if c := world.Me(player); c != nil && player != me && wind != nil {
+			world.Move(c, wind)
+		}
+	}
+}
+
+// opponents returns the living opponents
+func opponents(world *core.World, me string) []*core.Cloud {
+	var ret []*core.Cloud
+	for _, c := range world.Clouds() {
+		if c.Player != "" && c.Player != me && !c.IsDeath() {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// PolicyModel predicts the opponents with a bot.Policy (e.g. bot.Greedy: the opponents chase smaller clouds).
+type PolicyModel struct {
+	Policy bot.Policy
+}
+
+func (p *PolicyModel) Predict(world *core.World, me string) map[string]*core.Velocity {
+	ret := make(map[string]*core.Velocity)
+	for _, c := range opponents(world, me) {
+		if wind := p.Policy.Act(world, c); wind != nil {
+			ret[c.Player] = wind
+		}
+	}
+	return ret
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// Observed predicts that the opponents repeat their last move command (last observed behaviour).
+// A move is detected by the change of velocity and vapor between two decisions,
+// and it is repeated for Memory seconds (game time).
+type Observed struct {
+	Memory float64 // DEFAULT: 1
+
+	last  map[string]*core.Cloud // copies of the opponents at the last decision (the world executes the predicted moves)
+	moves map[string]observation
+}
+
+type observation struct {
+	wind      *core.Velocity
+	iteration uint64
+}
+
+func (o *Observed) Predict(world *core.World, me string) map[string]*core.Velocity {
+	if o.last == nil {
+		o.last = make(map[string]*core.Cloud)
+		o.moves = make(map[string]observation)
+	}
+	iteration, _, _, _, _ := world.Stats()
+	memory := uint64(o.Memory * float64(world.GameSpeed()))
+
+	ret := make(map[string]*core.Velocity)
+	for _, c := range opponents(world, me) {
+		if prev, ok := o.last[c.Player]; ok {
+			if wind := detectMove(prev, c); wind != nil {
+				o.moves[c.Player] = observation{wind: wind, iteration: iteration}
+			}
+		}
+		o.last[c.Player] = core.NewCloud(nil, core.NewPosition(c.Pos.X, c.Pos.Y), core.NewVelocity(c.Vel.X, c.Vel.Y), c.Vapor, c.Player, c.Color)
+		if m, ok := o.moves[c.Player]; ok && iteration-m.iteration <= memory {
+			ret[c.Player] = m.wind
+		}
+	}
+	return ret
+}
+
+// detectMove estimates the move command between two states of a cloud (nil: no move).
+// A move adds wind * 5 / radius to the velocity and costs the strength of the wind in vapor.
+func detectMove(prev, c *core.Cloud) *core.Velocity {
+	dx, dy := c.Vel.X-prev.Vel.X, c.Vel.Y-prev.Vel.Y
+	radius := c.Radius()
+	wind := core.NewVelocity(dx*radius/5, dy*radius/5)
+	strength := wind.Strength()
+	if strength < 1 || strength > prev.Vapor/2 {
+		return nil
+	}
+
+	// the vapor loss confirms the move (no bounce or collision)
+	loss := prev.Vapor - c.Vapor
+	if math.Abs(float64(loss-strength)) > 0.2*float64(strength) {
+		return nil
+	}
+	return wind
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// SelfPlay predicts the opponents with a fast SimAI (few actions, passive opponents).
+// Each opponent gets its own SimAI, which is created at the first prediction and simulates on a single goroutine
+// (the prediction is part of a decision of the outer SimAI).
+type SelfPlay struct {
+	config Config
+	ais    map[string]*SimAI // player name -> SimAI
+}
+
+// NewSelfPlay creates the opponent model with the strategies of a config (see NewSimAIConfig).
+func NewSelfPlay(c Config) (*SelfPlay, error) {
+	if _, _, _, _, err := c.strategies(); err != nil {
+		return nil, err
+	}
+	return &SelfPlay{config: c}, nil
+}
+
+func (s *SelfPlay) Predict(world *core.World, me string) map[string]*core.Velocity {
+	if s.ais == nil {
+		s.ais = make(map[string]*SimAI)
+	}
+	ret := make(map[string]*core.Velocity)
+	for _, c := range opponents(world, me) {
+		ai, ok := s.ais[c.Player]
+		if !ok {
+			ai, _ = NewSimAIConfig(c.Player, c.Color, s.config) // validated by NewSelfPlay
+			ai.cpus = 1
+			s.ais[c.Player] = ai
+		}
+		if a, _ := ai.plan(world.Clone(), time.Time{}); a.Strength > 0 {
+			ret[c.Player] = a.Wind
+		}
+	}
+	return ret
+}
@@ -0,0 +1,92 @@
+package simai
+
+import (
+	"CloudWars/core"
+	"CloudWars/remote"
+	"fmt"
+	"math"
+)
+
+// prediction compares the predicted world of the last ai loop with the server world
+// to measure the accuracy of the client simulation over time.
+//
+// The prediction keeps the server world of the last loop and the move command with its planned iteration
+// (after the lookahead). At the next loop, it is simulated to the iteration of the new server world, so the
+// checksums (see core.World Checksum) and the position of the own cloud are compared at the same iteration.
+// The checksums only match if the server executed the move at the planned iteration and the other players
+// didn't move.
+type prediction struct {
+	name    string
+	base    *core.World    // server world of the last ai loop (nil before the first loop)
+	at      uint64         // planned iteration of the move
+	wind    *core.Velocity // move command of the last ai loop (nil: no move)
+	checks  int            // compared worlds
+	matches int            // equal checksums
+	drift   float64        // sum of the position errors of the own cloud
+}
+
+// verify loads the server world and compares it with the prediction.
+func (p *prediction) verify(tcpClient *remote.TcpClient) *core.World {
+	server := &core.World{}
+	server.FromJson(tcpClient.List())
+	p.compare(server)
+	return server
+}
+
+// compare simulates the prediction to the iteration of the server world and compares both worlds.
+func (p *prediction) compare(server *core.World) {
+	if p.base == nil {
+		return // nothing to compare
+	}
+	local := p.base
+	p.base = nil
+	iteration, _, _, _, _ := server.Stats()
+	if i, _, _, _, _ := local.Stats(); i > iteration {
+		return // the server world is older
+	}
+
+	// simulate the prediction incl. the move
+	for {
+		i, _, _, _, _ := local.Stats()
+		if p.wind != nil && i >= p.at {
+			local.Move(local.Me(p.name), p.wind)
+			p.wind = nil
+		}
+		if i >= iteration {
+			break
+		}
+		local.Update()
+	}
+
+	// compare
+	p.checks++
+	if local.Checksum() == server.Checksum() {
+		p.matches++
+	}
+	if a, b := local.Me(p.name), server.Me(p.name); a != nil && b != nil {
+		p.drift += math.Hypot(float64(a.Pos.X-b.Pos.X), float64(a.Pos.Y-b.Pos.Y))
+	}
+}
+
+// start begins the prediction of the next loop with the server world and the planned iteration of the move.
+func (p *prediction) start(server *core.World, at uint64) {
+	p.base = server
+	p.at = at
+	p.wind = nil
+}
+
+// move adds the move command to the prediction.
+func (p *prediction) move(wind *core.Velocity) {
+	if p.base != nil && wind.Strength() > 0 {
+		p.wind = wind
+	}
+}
+
+// String returns the prediction accuracy.
+func (p *prediction) String() string {
+	if p.checks == 0 {
+		return fmt.Sprintf("PREDICTION %s: no data", p.name)
+	}
+	return fmt.Sprintf("PREDICTION %s: %d checks, checksum match rate %.1f %%, position error %.2f", p.name, p.checks,
+		float64(p.matches)/float64(p.checks)*100, p.drift/float64(p.checks))
+}
@@ -0,0 +1,215 @@
+package simai
+
+import (
+	"CloudWars/core"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// step is a move command of a sequence, At seconds after the decision
+type step struct {
+	Wind *core.Velocity
+	At   float64
+}
+
+// sequence is a plan of move commands (e.g. move now, move again after 0.3 s) and the evaluation of its future.
+// The steps are executed on a clone of the world, then the rollout of the last move is added to the results.
+type sequence struct {
+	steps   []step
+	points  float64
+	results []Result
+}
+
+// shift returns the sequence relative to a decision elapsed seconds later (nil: nothing left)
+func (q *sequence) shift(elapsed float64) *sequence {
+	if q == nil {
+		return nil
+	}
+	ret := &sequence{points: math.Inf(-1)}
+	for _, st := range q.steps {
+		ret.steps = append(ret.steps, step{Wind: st.Wind, At: math.Max(0, st.At-elapsed)}) // too late: now
+	}
+	if len(ret.steps) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// next returns the steps after the first move or the whole sequence if the first move is in the future
+func (q *sequence) next() *sequence {
+	rest := q.steps
+	if rest[0].At <= 0 {
+		rest = rest[1:] // executed now
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	return &sequence{steps: rest, points: q.points}
+}
+
+// strength is the vapor used by all moves
+func (q *sequence) strength() float32 {
+	var sum float32
+	for _, st := range q.steps {
+		sum += st.Wind.Strength()
+	}
+	return sum
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// search returns the best sequence: the simulated single moves and the warm start are extended by
+// one move per depth (beam search) until the Depth or the deadline is reached
+func (s *SimAI) search(world *core.World, actionsList []actions, warm *sequence, deadline time.Time) *sequence {
+	me := world.Me(s.name)
+
+	// single moves
+	var pool []*sequence
+	for _, aa := range actionsList {
+		for _, a := range aa {
+			if a.Results != nil {
+				pool = append(pool, &sequence{steps: []step{{Wind: a.Wind}}, points: a.EvaluationPoints, results: a.Results})
+			}
+		}
+	}
+	if warm != nil {
+		s.evaluate(world, me, warm)
+		pool = append(pool, warm)
+	}
+	if len(pool) == 0 {
+		return &sequence{steps: []step{{Wind: core.NewVelocity(0, 0)}}} // no simulation in time
+	}
+	sortSequences(pool)
+	best, beam := pool[0], pool
+
+	// iterative deepening
+	for depth := 2; depth <= s.Depth; depth++ {
+		if !deadline.IsZero() && deadline.Before(time.Now()) {
+			break
+		}
+		if len(beam) > s.Beam {
+			beam = beam[:s.Beam]
+		}
+		next := s.deepen(world, me, beam, deadline)
+		if len(next) == 0 {
+			break
+		}
+		if next[0].points > best.points {
+			best = next[0]
+		}
+		beam = next
+	}
+	return best
+}
+
+// deepen extends every sequence of the beam with Samples follow-up moves Delay seconds after its last move
+// and returns the simulated sequences (best first)
+func (s *SimAI) deepen(world *core.World, me *core.Cloud, beam []*sequence, deadline time.Time) []*sequence {
+	winds := s.Generator.Actions(world, me)
+	var candidates []*sequence
+	for _, q := range beam {
+		last := q.steps[len(q.steps)-1].At
+		samples := 0
+		perm := rand.Perm
+		if s.rnd != nil {
+			perm = s.rnd.Perm
+		}
+		for _, i := range perm(len(winds)) {
+			if samples >= s.Samples {
+				break
+			}
+			if winds[i].Strength() == 0 {
+				continue // no move: same as the sequence
+			}
+			steps := append(append([]step{}, q.steps...), step{Wind: winds[i], At: last + s.Delay})
+			candidates = append(candidates, &sequence{steps: steps, points: math.Inf(-1)})
+			samples++
+		}
+	}
+
+	// simulate on all cpus
+	wg := new(sync.WaitGroup)
+	wg.Add(s.cpus)
+	for c := 0; c < s.cpus; c++ {
+		go func(c int) {
+			defer wg.Done()
+			for i := c; i < len(candidates); i += s.cpus {
+				if !deadline.IsZero() && deadline.Before(time.Now()) {
+					return
+				}
+				s.evaluate(world, me, candidates[i])
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	// simulated sequences
+	ret := candidates[:0]
+	for _, q := range candidates {
+		if q.results != nil {
+			ret = append(ret, q)
+		}
+	}
+	sortSequences(ret)
+	return ret
+}
+
+// evaluate simulates the sequence on a clone of the world and scores the results
+func (s *SimAI) evaluate(world *core.World, originMe *core.Cloud, q *sequence) {
+	w := world.Clone()
+	me := w.Me(s.name)
+	startVapor := me.Vapor
+	startEnemies := countEnemies(w, s.name)
+
+	// moves before the last one
+	var t float64
+	for _, st := range q.steps[:len(q.steps)-1] {
+		advance(w, st.At-t)
+		t = st.At
+		w.Move(me, st.Wind)
+	}
+	last := q.steps[len(q.steps)-1]
+	advance(w, last.At-t)
+
+	// rollout of the last move (the results are relative to the decision)
+	gainVapor := me.Vapor - startVapor
+	deadEnemies := startEnemies - countEnemies(w, s.name)
+	alive := !me.IsDeath()
+	results := s.Rollout.Rollout(w, s.name, last.Wind)
+	for i := range results {
+		results[i].GainVapor += gainVapor
+		results[i].DeadEnemies += deadEnemies
+		results[i].Alive = results[i].Alive && alive
+		results[i].UsedWind = q.strength()
+	}
+	q.results = results
+	q.points = s.Evaluator.Evaluate(originMe, core.NewVelocity(q.strength(), 0), results)
+}
+
+// advance updates the world for sec seconds (see SimSpeedUp)
+func advance(w *core.World, sec float64) {
+	speedUp := w.SimSpeedUp
+	if speedUp < 1 {
+		speedUp = 1
+	}
+	ticks := sec * float64(w.GameSpeed()) / float64(speedUp)
+	for i := 0; i < int(math.Round(ticks)); i++ {
+		w.Update()
+	}
+}
+
+func sortSequences(qq []*sequence) {
+	sort.SliceStable(qq, func(i, j int) bool { return qq[i].points > qq[j].points })
+}
+
+func logSequence(q *sequence) {
+	fmt.Printf("plan %.0f Points:", q.points)
+	for _, st := range q.steps {
+		fmt.Printf("  %.1f s: %.0f Wind", st.At, st.Wind.Strength())
+	}
+	fmt.Printf("\n")
+}
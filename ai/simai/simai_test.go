@@ -0,0 +1,449 @@
+package simai
+
+import (
+	"CloudWars/core"
+	"CloudWars/debug"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// fixedSimAI returns a deterministic SimAI (no shuffle, no deadline)
+func fixedSimAI(t *testing.T, c Config) *SimAI {
+	s, err := NewSimAIConfig("A", "blue", c)
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if g, ok := s.Generator.(*Grid); ok {
+		g.Shuffle = false
+	}
+	return s
+}
+
+func TestSimAI_Food(t *testing.T) {
+	// a neutral cloud on the right
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(300, 300), 600)
+	w.AddPlayer("B", "red", core.NewPosition(900, 500), 600)
+	food := w.AddPlayer("", "", core.NewPosition(420, 300), 200)
+	food.Player = "" // neutral
+
+	a := fixedSimAI(t, Config{}).Act(w.Clone())
+	if a.Wind == nil || a.Wind.X <= 0 || math.Abs(float64(a.Wind.Y)) > math.Abs(float64(a.Wind.X)) {
+		t.Errorf("fail: %v", a.Wind)
+	}
+}
+
+func TestSimAI_Threat(t *testing.T) {
+	// a large enemy from the left
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 400)
+	b := w.AddPlayer("B", "red", core.NewPosition(380, 300), 2000)
+	b.Vel = core.NewVelocity(30, 0)
+
+	s := fixedSimAI(t, Config{})
+	best, _ := s.plan(w.Clone(), time.Time{})
+	if best.Wind.X <= 0 || best.EvaluationPoints <= DefaultWeights.Death/2 || !best.Results[1].Alive {
+		t.Errorf("fail: %v %v %+v", best.Wind, best.EvaluationPoints, best.Results)
+	}
+
+	// without a move: death
+	nothing := s.Rollout.Rollout(w.Clone(), "A", core.NewVelocity(0, 0))
+	if len(nothing) != 3 || nothing[2].Alive {
+		t.Errorf("fail: %+v", nothing)
+	}
+}
+
+func TestSimAI_SubStepping(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 40, 20, 60, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 400)
+
+	s := fixedSimAI(t, Config{AngleStep: 90, Strengths: []float32{100}, SubStepping: true})
+	sim := w.Clone()
+	best, _ := s.plan(sim, time.Time{})
+	if !sim.SubStepping {
+		t.Errorf("fail: sub-stepping off")
+	}
+
+	// the accelerated rollouts are the same as the rollouts without speed up
+	exact := w.Clone()
+	exact.SimSpeedUp = 1
+	results := s.Rollout.Rollout(exact, "A", best.Wind)
+	for i, r := range results {
+		b := best.Results[i]
+		if r.GainVapor != b.GainVapor || r.GainSpeed != b.GainSpeed || r.Alive != b.Alive {
+			t.Errorf("fail: %d: %+v != %+v", i, r, b)
+		}
+	}
+}
+
+// lazy never moves
+type lazy struct{}
+
+func (lazy) Evaluate(me *core.Cloud, wind *core.Velocity, results []Result) float64 {
+	return -float64(wind.Strength())
+}
+
+func TestPrediction(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 10, 5, 50, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 400)
+	wind := core.NewVelocity(30, 0)
+
+	// the server executes the move at the planned iteration: same checksum
+	p := &prediction{name: "A"}
+	p.start(w.Clone(), 5)
+	p.move(wind)
+	server := w.Clone()
+	for i := 0; i < 12; i++ {
+		if i == 5 {
+			server.Move(server.Me("A"), wind)
+		}
+		server.Update()
+	}
+	p.compare(server)
+	if p.checks != 1 || p.matches != 1 || p.drift != 0 {
+		t.Errorf("fail: %v", p)
+	}
+
+	// the server executes the move later: compared at the same iteration, but different
+	p.start(server.Clone(), 14)
+	p.move(wind)
+	next := server.Clone()
+	for i := 0; i < 8; i++ {
+		if i == 6 {
+			next.Move(next.Me("A"), wind)
+		}
+		next.Update()
+	}
+	p.compare(next)
+	if p.checks != 2 || p.matches != 1 || p.drift <= 0 {
+		t.Errorf("fail: %v", p)
+	}
+	if s := p.String(); s != "PREDICTION A: 2 checks, checksum match rate 50.0 %, position error "+fmt.Sprintf("%.2f", p.drift/2) {
+		t.Errorf("fail: %s", s)
+	}
+
+	// the server world is older: no comparison
+	p.start(next.Clone(), 30)
+	p.compare(server)
+	if p.checks != 2 {
+		t.Errorf("fail: %v", p)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	// own evaluator
+	Evaluators["lazy"] = func(c Config) Evaluator { return lazy{} }
+	defer delete(Evaluators, "lazy")
+
+	w := core.NewWorld(1000, 600, 60, 20, 5, 200, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 600)
+	s := fixedSimAI(t, Config{Evaluator: "lazy", Strengths: []float32{10, 50}, AngleStep: 90, Horizons: []float64{0.5}})
+	if a := s.Act(w.Clone()); a.Wind != nil || a.Kill {
+		t.Errorf("fail: %+v", a)
+	}
+	if n := len(s.Generator.Actions(w, w.Me("A"))); n != 1+2*4 {
+		t.Errorf("fail: %d actions", n)
+	}
+
+	// unknown strategies
+	for _, c := range []Config{{Generator: "x"}, {Rollout: "x"}, {Evaluator: "x"}, {Opponents: "x"}} {
+		if _, err := NewSimAIConfig("A", "blue", c); err == nil {
+			t.Errorf("fail: %+v", c)
+		}
+	}
+}
+
+func TestWeights_Evaluate(t *testing.T) {
+	me := &core.Cloud{Vapor: 100}
+	alive := []Result{{Alive: true, GainVapor: 10}, {Alive: true, GainVapor: 20}, {Alive: true, GainVapor: 40}}
+	ws := DefaultWeights
+
+	// max(10 %, 20 % * 0.75, 40 % * 0.3) - 10 % wind
+	if p := ws.Evaluate(me, core.NewVelocity(10, 0), alive); math.Abs(p-5) > 1e-6 {
+		t.Errorf("fail: %v", p)
+	}
+
+	// death and kills
+	dead := []Result{{Alive: true}, {Alive: false, DeadEnemies: 1}, {Alive: false, DeadEnemies: 1}}
+	if p := ws.Evaluate(me, core.NewVelocity(0, 0), dead); p != -1000+40 {
+		t.Errorf("fail: %v", p)
+	}
+	if p := ws.Evaluate(me, core.NewVelocity(0, 0), alive[:2]); !math.IsInf(p, -1) {
+		t.Errorf("fail: %v", p)
+	}
+}
+
+func TestOpponents(t *testing.T) {
+	// a large enemy on the left (not moving)
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 400)
+	w.AddPlayer("B", "red", core.NewPosition(380, 300), 2000)
+	w.SimSpeedUp = 10
+	rollout := &Horizons{Seconds: []float64{0.6, 1, 2}}
+
+	// passive: no move is safe
+	if r := rollout.Rollout(w.Clone(), "A", core.NewVelocity(0, 0)); !r[2].Alive {
+		t.Errorf("fail: %+v", r)
+	}
+
+	// greedy: B chases A
+	for _, name := range []string{"greedy", "simai"} {
+		o := OpponentModels[name](Config{})
+		p := o.Predict(w, "A")
+		if len(p) != 1 || p["B"] == nil || p["B"].X <= 0 {
+			t.Errorf("fail: %s %v", name, p)
+		}
+		c := w.Clone()
+		predict(c, "A", o)
+		if r := rollout.Rollout(c, "A", core.NewVelocity(0, 0)); r[2].Alive {
+			t.Errorf("fail: %s %+v", name, r)
+		}
+	}
+
+	// self-play: one single cpu SimAI per opponent, reused; invalid configs fail at construction
+	o, err := NewSelfPlay(Config{AngleStep: 45, Strengths: []float32{50, 200}})
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	o.Predict(w, "A")
+	ai := o.ais["B"]
+	o.Predict(w, "A")
+	if len(o.ais) != 1 || o.ais["B"] != ai || ai.cpus != 1 {
+		t.Errorf("fail: %v", o.ais)
+	}
+	if _, err := NewSelfPlay(Config{Generator: "x"}); err == nil {
+		t.Errorf("fail: unknown generator")
+	}
+}
+
+func TestObserved(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 400)
+	b := w.AddPlayer("B", "red", core.NewPosition(200, 300), 1000)
+	o := &Observed{Memory: 1}
+
+	// no move yet
+	if p := o.Predict(w, "A"); len(p) != 0 {
+		t.Errorf("fail: %v", p)
+	}
+
+	// B moves to the right
+	w.Move(b, core.NewVelocity(50, 0))
+	for i := 0; i < 6; i++ {
+		w.Update()
+	}
+	p := o.Predict(w, "A")
+	if len(p) != 1 || math.Abs(float64(p["B"].X-50)) > 2 || math.Abs(float64(p["B"].Y)) > 1 {
+		t.Errorf("fail: %v", p["B"])
+	}
+
+	// the move is repeated for 1 second
+	for i := 0; i < 61; i++ {
+		w.Update()
+	}
+	if p := o.Predict(w, "A"); len(p) != 0 {
+		t.Errorf("fail: %v", p)
+	}
+}
+
+func TestObserved_plan(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 300), 400)
+	b := w.AddPlayer("B", "red", core.NewPosition(200, 300), 1000)
+	s, err := NewSimAIConfig("A", "blue", Config{AngleStep: 90, Strengths: []float32{50}, Opponents: "observed"})
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	o := s.Opponents.(*Observed)
+
+	// every decision plans on a clone, the predicted moves are executed there (not on the observed state)
+	s.plan(w.Clone(), time.Time{})
+	for i := 1; i <= 2; i++ {
+		w.Move(b, core.NewVelocity(50, 0))
+		for j := 0; j < 6; j++ {
+			w.Update()
+		}
+		s.plan(w.Clone(), time.Time{})
+		iteration, _, _, _, _ := w.Stats()
+		if m := o.moves["B"]; m.iteration != iteration || math.Abs(float64(m.wind.X-50)) > 2 {
+			t.Errorf("fail: move %d: %d %v", i, m.iteration, m.wind)
+		}
+	}
+}
+
+func TestTune(t *testing.T) {
+	out := t.TempDir() + "/simai.json"
+	c := TuneConfig{Population: 4, Generations: 2, Games: 1, Seconds: 5, Output: out,
+		Width: 800, Height: 400, NeutralAmount: 10,
+		Strategy: Config{AngleStep: 90, Strengths: []float32{50}}}
+
+	pop, err := Tune(c)
+	if err != nil || len(pop) != 4 || pop[0].Fitness < pop[3].Fitness {
+		t.Fatalf("fail: %v %+v", err, pop)
+	}
+
+	// the output is a SimAI config
+	l, err := LoadConfig(out)
+	if err != nil || l.Weights == nil || *l.Weights != pop[0].Weights || l.AngleStep != 90 {
+		t.Errorf("fail: %v %+v", err, l)
+	}
+	if _, err := NewSimAIConfig("A", "blue", l); err != nil {
+		t.Errorf("fail: %v", err)
+	}
+
+	// invalid
+	if _, err := Tune(TuneConfig{Population: 2, Players: 3}); err == nil {
+		t.Errorf("fail: players")
+	}
+}
+
+func TestMutate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	m := mutate(DefaultWeights, 0.2, rnd)
+	if m == DefaultWeights || m.Death >= 0 || m.ShortKill <= 0 {
+		t.Errorf("fail: %+v", m)
+	}
+
+	// every weight from one parent
+	c := crossover(DefaultWeights, m, rnd)
+	for i, f := range c.fields() {
+		if *f != *DefaultWeights.fields()[i] && *f != *m.fields()[i] {
+			t.Errorf("fail: %d %v", i, *f)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	q := &sequence{steps: []step{{Wind: core.NewVelocity(10, 0)}, {Wind: core.NewVelocity(0, 10), At: 0.3}}}
+
+	// first move now, the rest later
+	n := q.next()
+	if len(n.steps) != 1 || n.steps[0].At != 0.3 {
+		t.Errorf("fail: %+v", n)
+	}
+	if s := n.shift(0.1); len(s.steps) != 1 || math.Abs(s.steps[0].At-0.2) > 1e-9 {
+		t.Errorf("fail: %+v", s)
+	}
+	if s := n.shift(0.5); s.steps[0].At != 0 || s.next() != nil {
+		t.Errorf("fail: %+v", s)
+	}
+	if (*sequence)(nil).shift(0.1) != nil {
+		t.Errorf("fail: nil")
+	}
+}
+
+func TestSequence_Search(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(300, 300), 600)
+	w.AddPlayer("B", "red", core.NewPosition(900, 500), 600)
+	w.AddPlayer("", "", core.NewPosition(420, 300), 200)
+	s := fixedSimAI(t, Config{AngleStep: 45, Strengths: []float32{50, 100}, Depth: 3, Samples: 8})
+
+	// a single move is the same as the sequence with one step
+	world := w.Clone()
+	best, actionsList := s.plan(world, time.Time{})
+	q := &sequence{steps: []step{{Wind: best.Wind}}}
+	s.evaluate(world, world.Me("A"), q)
+	if math.Abs(q.points-best.EvaluationPoints) > 1e-6 {
+		t.Errorf("fail: %v != %v", q.points, best.EvaluationPoints)
+	}
+
+	// deeper sequences are at least as good
+	seq := s.search(world, actionsList, nil, time.Time{})
+	if seq.points < best.EvaluationPoints || len(seq.steps) > 3 || seq.steps[0].At != 0 {
+		t.Errorf("fail: %+v", seq)
+	}
+
+	// decide keeps the rest of the plan
+	wind := s.decide(w.Clone())
+	if wind.X <= 0 || (s.sequence != nil && s.sequence.steps[0].At < 0.3-1e-9) {
+		t.Errorf("fail: %v %+v", wind, s.sequence)
+	}
+}
+
+func TestIntercept(t *testing.T) {
+	me := &core.Cloud{Pos: core.NewPosition(0, 0), Vel: core.NewVelocity(0, 0), Vapor: 400}
+
+	// standing target: straight
+	target := &core.Cloud{Pos: core.NewPosition(100, 0), Vel: core.NewVelocity(0, 0), Vapor: 100}
+	if a := intercept(me, target, 50); math.Abs(a) > 1e-6 {
+		t.Errorf("fail: %v", a)
+	}
+
+	// moving target: lead, the relative velocity after the move points at the target
+	target.Vel = core.NewVelocity(0, 5)
+	a := intercept(me, target, 50)
+	w := byRadians(a, 50)
+	vx := w.X*5/me.Radius() - target.Vel.X
+	vy := w.Y*5/me.Radius() - target.Vel.Y
+	if a <= 0 || math.Abs(float64(vy)) > 1e-4 || vx <= 0 {
+		t.Errorf("fail: %v %v %v", a, vx, vy)
+	}
+
+	// too weak: cancel the lateral velocity
+	if a := intercept(me, target, 1); math.Abs(a-math.Pi/2) > 1e-6 {
+		t.Errorf("fail: %v", a)
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	me := w.AddPlayer("A", "blue", core.NewPosition(300, 300), 600)
+	w.AddPlayer("B", "red", core.NewPosition(300, 100), 2000)
+	w.AddPlayer("", "", core.NewPosition(420, 300), 200)
+	g := Generators["adaptive"](Config{Strengths: []float32{10, 50, 100}}).(*Adaptive)
+
+	// without feedback: max
+	all := g.Actions(w, me)
+	if len(all) != 451 || all[0].Strength() != 0 {
+		t.Errorf("fail: %d", len(all))
+	}
+	// aimed: threat (3 strengths x 5 directions), then target (3 strengths x 3 directions)
+	if all[1].Y <= 0 || math.Abs(float64(all[1].X)) > 1e-3 || all[16].X <= 0 {
+		t.Errorf("fail: %v %v", all[1], all[16])
+	}
+
+	// the rate limits the actions, the last best move is refined
+	g.Feedback(core.NewVelocity(-50, 0), 1000, 100*time.Millisecond)
+	all = g.Actions(w, me)
+	if len(all) != 90 || all[16].X != -50 {
+		t.Errorf("fail: %d %v", len(all), all[16])
+	}
+
+	// under load the escapes are kept
+	g.Feedback(nil, 10, 100*time.Millisecond)
+	if all = g.Actions(w, me); len(all) != 32 || all[15].Y <= 0 || all[16].X <= 0 {
+		t.Errorf("fail: %d %v %v", len(all), all[15], all[16])
+	}
+
+	// decision
+	s := fixedSimAI(t, Config{Generator: "adaptive"})
+	if a := s.Act(w.Clone()); a.Wind == nil || a.Wind.X <= 0 {
+		t.Errorf("fail: %+v", a)
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.AddPlayer("A", "blue", core.NewPosition(300, 300), 600)
+	w.AddPlayer("B", "red", core.NewPosition(300, 100), 2000)
+	food := w.AddPlayer("", "", core.NewPosition(420, 300), 200)
+
+	var a *debug.Annotation
+	s := fixedSimAI(t, Config{})
+	s.Debug = func(an *debug.Annotation) { a = an }
+	s.Act(w.Clone())
+
+	if a == nil || len(a.Candidates) != 5 || a.Candidates[0].X <= 0 || a.Candidates[0].Score < a.Candidates[4].Score {
+		t.Fatalf("fail: %+v", a)
+	}
+	if len(a.Paths) != 1 || len(a.Paths[0].Points) < 10 || a.Paths[0].Points[0].X != 300 || a.Target != food.UID {
+		t.Errorf("fail: %+v", a)
+	}
+	if len(a.Threats) != 1 || a.Threats[0].Y != 100 {
+		t.Errorf("fail: %+v", a.Threats)
+	}
+}
@@ -2,87 +2,87 @@ package simai
 
 import (
 	"CloudWars/core"
-	"fmt"
-	"log"
 	"sync"
 	"time"
 )
 
-func simulate(routine int, wg *sync.WaitGroup, deadline time.Time, originWorld *core.World, playerName string, actions actions) {
-	defer wg.Done() // mark done when exiting the function
+// Rollout simulates the future of a move command on a clone of the world and returns the results.
+type Rollout interface {
+	Rollout(world *core.World, name string, wind *core.Velocity) []Result
+}
+
+// Horizons executes the move and updates the world (other clouds don't move) for consecutive durations.
+// After each duration the result is recorded (DEFAULT: 0.6, 1 and 2 seconds: short, mid and long term).
+type Horizons struct {
+	Seconds []float64
+}
 
+func (h *Horizons) Rollout(world *core.World, name string, wind *core.Velocity) []Result {
 	// starting conditions to compare the results later
-	originMe := originWorld.Me(playerName)
-	var startIteration, _, _, _, _ = originWorld.Stats()
+	originMe := world.Me(name)
+	var startIteration, _, _, _, _ = world.Stats()
 	var startVapor = originMe.Vapor
 	var startSpeed = originMe.Vel.Strength()
-	var startEnemies = countEnemies(originWorld, playerName)
+	var startEnemies = countEnemies(world, name)
+	var strength = wind.Strength()
 
-	// simulation loop
-	simIter := 0
-	for _, a := range actions {
-		simIter++
+	// prepare
+	w := world.Clone() // clone fresh world
+	me := w.Me(name)   // find me in the clone world
+	w.Move(me, wind)   // set action
 
-		// deadline -> LOOP EXIT
-		if deadline.Before(time.Now()) {
-			break
+	// simulate terms
+	results := make([]Result, len(h.Seconds))
+	for term, sec := range h.Seconds {
+
+		// call updates for this term
+		ticks := sec * float64(w.GameSpeed()) / float64(w.SimSpeedUp)
+		for t := 0; t < int(ticks); t++ {
+			w.Update()
 		}
+		var endIteration, _, _, _, _ = w.Stats()
 
-		// prepare
-		w := originWorld.Clone() // clone fresh world
-		me := w.Me(playerName)   // find me in the clone world
-		w.Move(me, a.Wind)       // set action
+		// calc results for this term
+		results[term] = Result{
+			StartIteration: startIteration,
+			EndIteration:   endIteration,
+			UsedWind:       strength,
+			DeadEnemies:    startEnemies - countEnemies(w, name),
+			GainVapor:      me.Vapor - startVapor,
+			GainSpeed:      me.Vel.Strength() - startSpeed,
+			Alive:          !me.IsDeath(),
+		}
+	}
+	return results
+}
 
-		// simulate terms
-		for term, sec := range []float64{0.6, 1.0, 2.0} {
+//--------------------------------------------------------------------------------------------------------------------//
 
-			// call updates for this term
-			ticks := sec * float64(w.GameSpeed()) / float64(w.SimSpeedUp)
-			for t := 0; t < int(ticks); t++ {
-				w.Update()
-			}
-			var endIteration, _, _, _, _ = w.Stats()
+func simulate(wg *sync.WaitGroup, deadline time.Time, originWorld *core.World, playerName string, actions actions, rollout Rollout) {
+	defer wg.Done() // mark done when exiting the function
 
-			// calc results for this term
-			switch term {
-			case 0:
-				a.ShortTerm.StartIteration = startIteration
-				a.ShortTerm.EndIteration = endIteration
-				a.ShortTerm.UsedWind = a.Strength
-				a.ShortTerm.DeadEnemies = startEnemies - countEnemies(w, playerName)
-				a.ShortTerm.GainVapor = me.Vapor - startVapor
-				a.ShortTerm.GainSpeed = me.Vel.Strength() - startSpeed
-				a.ShortTerm.Alive = !me.IsDeath()
-			case 1:
-				a.MidTerm.StartIteration = startIteration
-				a.MidTerm.EndIteration = endIteration
-				a.MidTerm.UsedWind = a.Strength
-				a.MidTerm.DeadEnemies = startEnemies - countEnemies(w, playerName)
-				a.MidTerm.GainVapor = me.Vapor - startVapor
-				a.MidTerm.GainSpeed = me.Vel.Strength() - startSpeed
-				a.MidTerm.Alive = !me.IsDeath()
-			case 2:
-				a.LongTerm.StartIteration = startIteration
-				a.LongTerm.EndIteration = endIteration
-				a.LongTerm.UsedWind = a.Strength
-				a.LongTerm.DeadEnemies = startEnemies - countEnemies(w, playerName)
-				a.LongTerm.GainVapor = me.Vapor - startVapor
-				a.LongTerm.GainSpeed = me.Vel.Strength() - startSpeed
-				a.LongTerm.Alive = !me.IsDeath()
-			default:
-				log.Fatalf("err: simulate: invalid term: %d", term)
-			}
+	// simulation loop
+	for _, a := range actions {
+		// deadline -> LOOP EXIT
+		if !deadline.IsZero() && deadline.Before(time.Now()) {
+			break
 		}
 
+		a.Results = rollout.Rollout(originWorld, playerName, a.Wind)
 	}
+}
 
-	// simulation alarm
-	if routine == 0 {
-		p := float64(simIter) / float64(len(actions)) * 100.0
-		if p < 100 {
-			fmt.Printf("ALERT: Not enough computing power!  %.0f %%\n", p)
+// simulated counts the simulated actions
+func simulated(actionsList []actions) (done, all int) {
+	for _, aa := range actionsList {
+		for _, a := range aa {
+			all++
+			if a.Results != nil {
+				done++
+			}
 		}
 	}
+	return
 }
 
 func countEnemies(world *core.World, playerName string) (enemies int) {
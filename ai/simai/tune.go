@@ -0,0 +1,263 @@
+package simai
+
+import (
+	"CloudWars/bot"
+	"CloudWars/core"
+	"CloudWars/stats"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+// TuneConfig is the setup of an evolutionary tuning of the evaluation weights (json file).
+// A population of SimAI variants plays in-process games against each other (see bot.Arena),
+// the best weights of each generation survive and the next weights are crossovers and mutations of them.
+type TuneConfig struct {
+	Population  int     // weight vectors per generation (DEFAULT: 8)
+	Generations int     // DEFAULT: 10
+	Games       int     // games per weight vector and generation (DEFAULT: 2)
+	Players     int     // bots per game (DEFAULT: 2)
+	Elite       int     // best weight vectors that survive unchanged (DEFAULT: 2)
+	Mutation    float64 // standard deviation of the relative mutation of a weight (DEFAULT: 0.2)
+	Seed        int64   // random seed of the evolution, world seeds are Seed+1, Seed+2, ... (DEFAULT: 1)
+	Seconds     int     // game time limit of a game (DEFAULT: 60)
+	Output      string  // best weights as SimAI config file (DEFAULT: simai.json)
+
+	// strategies of the bots, the weights are the start of the evolution (DEFAULT: AngleStep 20, DefaultWeights)
+	Strategy Config
+
+	// game
+	Width           int // DEFAULT: 2048
+	Height          int // DEFAULT: 1152
+	Speed           int // DEFAULT: 60
+	PlayerVapor     int // DEFAULT: 600
+	NeutralAmount   int // DEFAULT: 100
+	NeutralMaxSpeed int // DEFAULT: 7
+	NeutralMaxVapor int // DEFAULT: 200
+}
+
+// Individual is a weight vector and its fitness (mean score of its games: 1 for a win, else half its vapor share).
+type Individual struct {
+	Weights Weights
+	Fitness float64
+}
+
+// LoadTuneConfig reads a tuning config file.
+func LoadTuneConfig(path string) (TuneConfig, error) {
+	var c TuneConfig
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// defaults sets all unset values
+func (c *TuneConfig) defaults() {
+	def := func(v *int, d int) {
+		if *v <= 0 {
+			*v = d
+		}
+	}
+	def(&c.Population, 8)
+	def(&c.Generations, 10)
+	def(&c.Games, 2)
+	def(&c.Players, 2)
+	def(&c.Elite, 2)
+	def(&c.Seconds, 60)
+	def(&c.Width, 2048)
+	def(&c.Height, 1152)
+	def(&c.Speed, 60)
+	def(&c.PlayerVapor, 600)
+	def(&c.NeutralAmount, 100)
+	def(&c.NeutralMaxSpeed, 7)
+	def(&c.NeutralMaxVapor, 200)
+	if c.Mutation <= 0 {
+		c.Mutation = 0.2
+	}
+	if c.Seed == 0 {
+		c.Seed = 1
+	}
+	if c.Output == "" {
+		c.Output = "simai.json"
+	}
+	if c.Strategy.AngleStep <= 0 {
+		c.Strategy.AngleStep = 20 // 91 actions: faster games
+	}
+}
+
+// validate checks the config
+func (c *TuneConfig) validate() error {
+	if c.Players < 2 || c.Players > 5 {
+		return errors.New("players must be between 2 and 5")
+	}
+	if c.Players > c.Population {
+		return errors.New("more players per game than weight vectors")
+	}
+	if c.Elite >= c.Population {
+		return errors.New("the elite must be smaller than the population")
+	}
+	_, _, _, _, err := c.Strategy.strategies()
+	return err
+}
+
+// Tune evolves the evaluation weights and writes the best weights to the output file.
+// It returns the last generation (best first).
+func Tune(c TuneConfig) ([]Individual, error) {
+	c.defaults()
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	rnd := rand.New(rand.NewSource(c.Seed))
+
+	// first generation: the start weights and their mutations
+	start := DefaultWeights
+	if c.Strategy.Weights != nil {
+		start = *c.Strategy.Weights
+	}
+	pop := make([]Individual, c.Population)
+	for i := range pop {
+		pop[i].Weights = start
+		if i > 0 {
+			pop[i].Weights = mutate(start, c.Mutation, rnd)
+		}
+	}
+
+	seed := c.Seed
+	for gen := 1; gen <= c.Generations; gen++ {
+		// games
+		for i := range pop {
+			pop[i].Fitness = 0
+		}
+		for g := 0; g < c.Games; g++ {
+			seed++
+			order := rnd.Perm(len(pop))
+			for len(order)%c.Players != 0 {
+				order = append(order, rnd.Intn(len(pop))) // fill the last game
+			}
+			counted := make(map[int]bool)
+			for i := 0; i < len(order); i += c.Players {
+				group := order[i : i+c.Players]
+				scores := c.play(pop, group, seed)
+				for j, p := range group {
+					if !counted[p] {
+						pop[p].Fitness += scores[j] / float64(c.Games)
+						counted[p] = true
+					}
+				}
+			}
+		}
+		sort.SliceStable(pop, func(i, j int) bool { return pop[i].Fitness > pop[j].Fitness })
+		fmt.Printf("GENERATION %d: best %.3f %+v\n", gen, pop[0].Fitness, pop[0].Weights)
+
+		// best weights so far
+		if err := c.write(pop[0].Weights); err != nil {
+			return pop, err
+		}
+		if gen == c.Generations {
+			break
+		}
+
+		// next generation: elite, crossovers and mutations
+		next := make([]Individual, 0, len(pop))
+		for i := 0; i < c.Elite; i++ {
+			next = append(next, Individual{Weights: pop[i].Weights})
+		}
+		for len(next) < len(pop) {
+			a, b := selectParent(pop, rnd), selectParent(pop, rnd)
+			next = append(next, Individual{Weights: mutate(crossover(a, b, rnd), c.Mutation, rnd)})
+		}
+		pop = next
+	}
+	return pop, nil
+}
+
+// play runs a game of the group and returns the scores of the players
+func (c *TuneConfig) play(pop []Individual, group []int, seed int64) []float64 {
+	world := core.NewWorld(c.Width, c.Height, c.Speed, c.NeutralAmount, float32(c.NeutralMaxSpeed), float32(c.NeutralMaxVapor), seed)
+	arena := bot.NewArena(world, 10)
+	arena.Limit = uint64(c.Seconds * c.Speed)
+	colors := []string{"blue", "gray", "orange", "purple", "red"}
+	names := make([]string, len(group))
+	for i, p := range group {
+		strategy := c.Strategy
+		strategy.Weights = &pop[p].Weights
+		names[i] = fmt.Sprintf("SimAI-%d", i)
+		ai, _ := NewSimAIConfig(names[i], colors[i], strategy) // see validate
+		arena.Add(ai, float32(c.PlayerVapor))
+	}
+	return scores(arena.Run(), names)
+}
+
+// write saves the weights as SimAI config
+func (c *TuneConfig) write(ws Weights) error {
+	out := c.Strategy
+	out.Weights = &ws
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.Output, append(b, '\n'), 0644)
+}
+
+// scores returns 1 for the winner, else half the share of the final vapor of all players
+func scores(r *stats.Report, names []string) []float64 {
+	var total float32
+	vapor := make(map[string]float32)
+	for _, p := range r.Players {
+		total += p.FinalVapor
+		vapor[p.Name] = p.FinalVapor
+	}
+	ret := make([]float64, len(names))
+	for i, name := range names {
+		if r.Winner == name {
+			ret[i] = 1
+		} else if total > 0 {
+			ret[i] = float64(vapor[name]/total) / 2
+		}
+	}
+	return ret
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// fields returns the weights as vector
+func (ws *Weights) fields() []*float64 {
+	return []*float64{&ws.ShortVapor, &ws.MidVapor, &ws.LongVapor, &ws.Wind,
+		&ws.Death, &ws.LongDeath, &ws.ShortKill, &ws.MidKill, &ws.LongKill}
+}
+
+// mutate multiplies every weight with a log-normal factor (the signs don't change)
+func mutate(ws Weights, sigma float64, rnd *rand.Rand) Weights {
+	for _, f := range ws.fields() {
+		*f *= math.Exp(rnd.NormFloat64() * sigma)
+	}
+	return ws
+}
+
+// crossover takes every weight from one of the parents (uniform crossover)
+func crossover(a, b Weights, rnd *rand.Rand) Weights {
+	fa, fb := a.fields(), b.fields()
+	for i := range fa {
+		if rnd.Intn(2) == 1 {
+			*fa[i] = *fb[i]
+		}
+	}
+	return a
+}
+
+// selectParent returns the better of two random individuals (tournament selection)
+func selectParent(pop []Individual, rnd *rand.Rand) Weights {
+	a, b := pop[rnd.Intn(len(pop))], pop[rnd.Intn(len(pop))]
+	if b.Fitness > a.Fitness {
+		return b.Weights
+	}
+	return a.Weights
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"CloudWars/core"
+	"CloudWars/stats"
+)
+
+// Arena runs a game with in-process bots as fast as possible (no network, no wall clock).
+type Arena struct {
+	world    *core.World
+	interval uint64 // iterations between two decisions
+	players  []*player
+
+	Limit uint64 // max iterations, the game is not decided after the limit (DEFAULT: 0 = until the game is decided)
+}
+
+type player struct {
+	bot   Bot
+	cloud *core.Cloud
+}
+
+// NewArena creates an arena for a world.
+// The bots decide frequency times per second (game time), like the poll limit of 10 requests per second of the server.
+func NewArena(world *core.World, frequency int) *Arena {
+	if frequency <= 0 {
+		frequency = 10
+	}
+	interval := uint64(world.GameSpeed() / frequency)
+	if interval == 0 {
+		interval = 1 // decision on every update
+	}
+	return &Arena{world: world, interval: interval}
+}
+
+// World returns the world of the arena.
+func (a *Arena) World() *core.World {
+	return a.world
+}
+
+// Add creates the player cloud of a bot (random position).
+func (a *Arena) Add(b Bot, vapor float32) *core.Cloud {
+	c := a.world.AddPlayer(b.Name(), b.Color(), nil, vapor)
+	a.players = append(a.players, &player{bot: b, cloud: c})
+	return c
+}
+
+// Run plays the game until it is decided (or the Limit is reached) and returns the statistics.
+func (a *Arena) Run() *stats.Report {
+	col := stats.NewCollector(a.world)
+	defer col.Close()
+
+	for {
+		iteration, _, _, winCondition, _ := a.world.Stats()
+		if winCondition || (a.Limit > 0 && iteration >= a.Limit) {
+			break
+		}
+
+		// decisions (all bots see the same iteration)
+		if iteration%a.interval == 0 {
+			a.decide()
+		}
+
+		a.world.Update()
+	}
+
+	return col.Report()
+}
+
+// decide asks all living bots for their actions and executes them
+func (a *Arena) decide() {
+	actions := make([]Action, len(a.players))
+	for i, p := range a.players {
+		if !p.cloud.IsDeath() {
+			actions[i] = p.bot.Act(a.world.Clone())
+		}
+	}
+
+	for i, p := range a.players {
+		if p.cloud.IsDeath() {
+			continue
+		}
+		if actions[i].Kill {
+			a.world.Kill(p.cloud)
+		} else if actions[i].Wind != nil {
+			a.world.Move(p.cloud, actions[i].Wind)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"CloudWars/core"
+	"CloudWars/remote"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testBot moves to the center of the game board and counts its decisions
+type testBot struct {
+	name      string
+	decisions int
+	kill      bool
+}
+
+func (b *testBot) Name() string  { return b.name }
+func (b *testBot) Color() string { return "red" }
+func (b *testBot) Act(world *core.World) Action {
+	b.decisions++
+	if b.kill {
+		return Action{Kill: true}
+	}
+	me := world.Me(b.name)
+	world.Update() // allowed: the world is a snapshot
+	if me.Vel.Strength() > 1 {
+		return Action{}
+	}
+	x := float32(world.Width())/2 - me.Pos.X
+	y := float32(world.Height())/2 - me.Pos.Y
+	return Action{Wind: core.NewVelocity(x/100, y/100)}
+}
+
+func TestArena(t *testing.T) {
+	world := core.NewWorld(2000, 1000, 60, 30, 5, 200, 1337)
+	a := NewArena(world, 10)
+	b1 := &testBot{name: "A"}
+	b2 := &testBot{name: "B"}
+	a.Add(b1, 600)
+	a.Add(b2, 600)
+
+	// game
+	start := time.Now()
+	r := a.Run()
+	iteration, _, _, winCondition, _ := world.Stats()
+	if !winCondition || r.Iterations != iteration || iteration > world.MaxIterations()+1 {
+		t.Errorf("fail: %d %v %+v", iteration, winCondition, r)
+	}
+	if time.Since(start) > 30*time.Second {
+		t.Errorf("fail: too slow %v", time.Since(start))
+	}
+
+	// 10 decisions per second (6 iterations)
+	if b1.decisions == 0 || uint64(b1.decisions) > iteration/6+1 {
+		t.Errorf("fail: %d decisions in %d iterations", b1.decisions, iteration)
+	}
+	if len(r.Players) != 2 || r.Players[0].Moves == 0 {
+		t.Errorf("fail: %+v", r.Players)
+	}
+}
+
+func TestArena_Limit(t *testing.T) {
+	world := core.NewWorld(2000, 1000, 60, 30, 5, 200, 1337)
+	a := NewArena(world, 10)
+	a.Add(&testBot{name: "A"}, 600)
+	a.Add(&testBot{name: "B"}, 600)
+	a.Limit = 120
+
+	r := a.Run()
+	if r.Iterations != 120 || r.Winner != "" {
+		t.Errorf("fail: %+v", r)
+	}
+}
+
+func TestRunTcp(t *testing.T) {
+	port := freePort(t)
+	world := core.NewWorld(2000, 1000, 60, 30, 20, 400, 1337)
+	go remote.RunServer("localhost", port, 800, world, 1, nil)
+	waitPort(t, port)
+	ticker := time.NewTicker(time.Second / 60)
+	stop := make(chan bool)
+	defer func() {
+		ticker.Stop()
+		close(stop)
+	}()
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				world.Update()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// the bot kills its own cloud, RunTcp returns with the next decision
+	b := &testBot{name: "Killer", kill: true}
+	done := make(chan error)
+	go func() { done <- RunTcp("localhost", port, b) }()
+	select {
+	case err := <-done:
+		if err != nil || b.decisions != 1 {
+			t.Errorf("fail: %v %d", err, b.decisions)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("fail: timeout")
+	}
+}
+
+// freePort returns a free tcp port of localhost
+func freePort(t *testing.T) string {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+// waitPort waits until the server accepts connections
+func waitPort(t *testing.T, port string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", "localhost:"+port, time.Second)
+		if err == nil {
+			_, _ = conn.Write([]byte("quit\n"))
+			conn.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fail: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
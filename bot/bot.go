@@ -0,0 +1,22 @@
+package bot
+
+import (
+	"CloudWars/core"
+)
+
+// Bot is an AI that controls a player cloud.
+// The same bot runs in-process (see Arena) or against a game server (see RunTcp).
+type Bot interface {
+	Name() string  // player name
+	Color() string // 'blue', 'gray', 'orange', 'purple' or 'red'
+
+	// Act returns the next action of the bot.
+	// The world is a snapshot (clone) of the game, the bot is allowed to change it (e.g. simulate the future).
+	Act(world *core.World) Action
+}
+
+// Action is the decision of a bot.
+type Action struct {
+	Wind *core.Velocity // move command (nil: no move)
+	Kill bool           // kill the own cloud
+}
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"CloudWars/core"
+	"math"
+)
+
+// Policy predicts the move command of an opponent in a simulation (nil: no move).
+// The AIs use it as opponent model (see mcts and simai).
+type Policy interface {
+	Act(world *core.World, cloud *core.Cloud) *core.Velocity
+}
+
+// Passive opponents don't move (like the rollouts of the SimAI).
+type Passive struct{}
+
+func (Passive) Act(world *core.World, cloud *core.Cloud) *core.Velocity {
+	return nil
+}
+
+// Greedy opponents chase the nearest smaller cloud within Range.
+// They accelerate toward the target with Share of their vapor if they are not fast enough in its direction.
+type Greedy struct {
+	Range float32 // DEFAULT: 300
+	Share float32 // DEFAULT: 0.05
+	Speed float32 // speed toward the target without a new move (DEFAULT: 20)
+}
+
+// NewGreedy creates a Greedy policy with the default settings.
+func NewGreedy() *Greedy {
+	return &Greedy{Range: 300, Share: 0.05, Speed: 20}
+}
+
+func (g *Greedy) Act(world *core.World, cloud *core.Cloud) *core.Velocity {
+	// nearest prey
+	var target *core.Cloud
+	var distance float32
+	for _, o := range world.Clouds() {
+		if o.UID == cloud.UID || o.IsDeath() || o.Vapor >= cloud.Vapor {
+			continue
+		}
+		d := dist(cloud.Pos, o.Pos) - cloud.Radius() - o.Radius()
+		if d < g.Range && (target == nil || d < distance) {
+			target, distance = o, d
+		}
+	}
+	if target == nil {
+		return nil
+	}
+
+	// direction
+	dx, dy := target.Pos.X-cloud.Pos.X, target.Pos.Y-cloud.Pos.Y
+	l := float32(math.Hypot(float64(dx), float64(dy)))
+	if l == 0 {
+		return nil
+	}
+	dx, dy = dx/l, dy/l
+
+	// fast enough
+	if cloud.Vel.X*dx+cloud.Vel.Y*dy >= g.Speed {
+		return nil
+	}
+	strength := cloud.Vapor * g.Share
+	return core.NewVelocity(dx*strength, dy*strength)
+}
+
+func dist(a, b *core.Position) float32 {
+	return float32(math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y)))
+}
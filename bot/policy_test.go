@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"CloudWars/core"
+	"math"
+	"testing"
+)
+
+func TestGreedy(t *testing.T) {
+	w := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	b := w.AddPlayer("B", "red", core.NewPosition(500, 300), 1000)
+	w.AddPlayer("A", "blue", core.NewPosition(500, 500), 400)
+
+	// chase the smaller player below
+	v := NewGreedy().Act(w, b)
+	if v == nil || v.Y <= 0 || math.Abs(float64(v.Strength()-50)) > 1e-3 {
+		t.Errorf("fail: %v", v)
+	}
+
+	// fast enough
+	b.Vel = core.NewVelocity(0, 30)
+	if v := NewGreedy().Act(w, b); v != nil {
+		t.Errorf("fail: %v", v)
+	}
+
+	// out of range
+	if v := NewGreedy().Act(w, w.Me("A")); v != nil {
+		t.Errorf("fail: %v", v)
+	}
+}
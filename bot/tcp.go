@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"CloudWars/core"
+	"CloudWars/remote"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RunTcp plays a game with a bot on a game server (see remote.TcpClient).
+// The bot decides 10 times per second (poll limit of the server) until its cloud is dead or the game is decided.
+// On a lock-step server, the bot decides once per step instead (see remote.RunLockstepServer).
+func RunTcp(host, port string, b Bot) error {
+	// connect to server and start game
+	tc := remote.NewTcpClient(host, port)
+	defer tc.Close()
+	tc.Name(b.Name())
+	tc.Color(b.Color())
+	if resp := tc.Play(); !strings.HasPrefix(resp, "ok") {
+		return fmt.Errorf("%s: %s", b.Name(), resp)
+	}
+
+	// lock-step server: the world waits for the move
+	lockstep := strings.HasPrefix(tc.Step(), "ok")
+
+	// bot loop
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		// world status
+		w := new(core.World)
+		w.FromJson(tc.List())
+		me := w.Me(b.Name())
+		if _, _, _, winCondition, _ := w.Stats(); winCondition || me == nil || me.IsDeath() {
+			return nil
+		}
+
+		// decision
+		a := b.Act(w)
+		if a.Kill {
+			tc.Kill()
+		} else if a.Wind != nil {
+			tc.Move(a.Wind)
+		}
+
+		// lock-step: wait for the next step, else for the next poll
+		if lockstep {
+			tc.Step()
+		} else {
+			<-tick.C
+		}
+	}
+}
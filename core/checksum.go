@@ -0,0 +1,103 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// Checksum returns a hash of the world state to detect desynchronisation between
+// the server and a client simulation. It is also part of the json world (hex string).
+//
+// FNV-1a (64 bit) over the iteration (uint64) followed by all clouds in world order:
+// the UID, a zero byte, and the float32 bits of Pos.X, Pos.Y, Vel.X, Vel.Y and Vapor (uint32).
+// All numbers are little-endian.
+func (w *World) Checksum() uint64 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.checksum()
+}
+
+// Verify updates the world until it reaches the given iteration and compares the checksums.
+// It returns false if the world is already ahead or the simulation has drifted from the checksum.
+func (w *World) Verify(iteration, checksum uint64) bool {
+	for {
+		w.mux.Lock()
+		i := w.iteration
+		w.mux.Unlock()
+
+		if i >= iteration {
+			break
+		}
+		w.Update()
+	}
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.iteration == iteration && w.checksum() == checksum
+}
+
+// checksum is the helper of Checksum (not thread-safe)
+func (w *World) checksum() uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(buf, w.iteration)
+	_, _ = h.Write(buf)
+
+	for _, c := range w.clouds {
+		_, _ = h.Write([]byte(c.UID))
+		_, _ = h.Write([]byte{0})
+		for _, v := range []float32{c.Pos.X, c.Pos.Y, c.Vel.X, c.Vel.Y, c.Vapor} {
+			binary.LittleEndian.PutUint32(buf, math.Float32bits(v))
+			_, _ = h.Write(buf[:4])
+		}
+	}
+
+	return h.Sum64()
+}
+
+// cloudUID derives the Unique Identifier from the iteration, the number of clouds spawned before
+// and the initial state of a new cloud.
+// A client simulation creates the same UIDs as the server (e.g. for exhaust clouds),
+// so that the checksums of both worlds are equal.
+func cloudUID(iteration, spawned uint64, c *Cloud) string {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(buf, iteration)
+	_, _ = h.Write(buf)
+	binary.LittleEndian.PutUint64(buf, spawned)
+	_, _ = h.Write(buf)
+	_, _ = h.Write([]byte(c.Player))
+	_, _ = h.Write([]byte{0})
+	for _, v := range []float32{c.Pos.X, c.Pos.Y, c.Vel.X, c.Vel.Y, c.Vapor} {
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(v))
+		_, _ = h.Write(buf[:4])
+	}
+
+	binary.LittleEndian.PutUint64(buf, h.Sum64())
+	return base64.StdEncoding.EncodeToString(buf[:6])
+}
+
+// itemUID derives the Unique Identifier of an item from the seed of the power-ups, the iteration,
+// the number of clouds spawned before and the number of items spawned before in the same update (see cloudUID).
+func itemUID(seed int64, iteration, spawned uint64, n int) string {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(buf, uint64(seed))
+	_, _ = h.Write(buf)
+	binary.LittleEndian.PutUint64(buf, iteration)
+	_, _ = h.Write(buf)
+	binary.LittleEndian.PutUint64(buf, spawned)
+	_, _ = h.Write(buf)
+	binary.LittleEndian.PutUint64(buf, uint64(n))
+	_, _ = h.Write(buf)
+
+	binary.LittleEndian.PutUint64(buf, h.Sum64())
+	return base64.StdEncoding.EncodeToString(buf[:6])
+}
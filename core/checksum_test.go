@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestWorld_Checksum(t *testing.T) {
+	w1 := initTestWorld()
+	w1.Update()
+	sum := w1.Checksum()
+
+	// clone and json
+	w2 := new(World)
+	w2.FromJson(w1.ToJson())
+	if w1.Clone().Checksum() != sum || w2.Checksum() != sum {
+		t.Errorf("fail: %x %x %x", sum, w1.Clone().Checksum(), w2.Checksum())
+	}
+	var jw jsonWorld
+	if err := json.Unmarshal([]byte(w1.ToJson()), &jw); err != nil || jw.Checksum != fmt.Sprintf("%016x", sum) {
+		t.Errorf("fail: %v %v", jw.Checksum, err)
+	}
+
+	// any change
+	w2.clouds[3].Vel.X += 0.0001
+	if w2.Checksum() == sum {
+		t.Errorf("fail: velocity")
+	}
+	w2 = w1.Clone()
+	w2.Update()
+	if w2.Checksum() == sum {
+		t.Errorf("fail: iteration")
+	}
+
+	// the same moves create the same exhaust clouds (UIDs)
+	w2 = w1.Clone()
+	w1.Move(w1.Me("Player 1"), NewVelocity(10, 5))
+	w2.Move(w2.Me("Player 1"), NewVelocity(10, 5))
+	if w1.Checksum() != w2.Checksum() {
+		t.Errorf("fail: move")
+	}
+
+	// identical clouds of the same iteration have different UIDs
+	a := NewCloud(w1, NewPosition(50, 50), NewVelocity(0, 0), 10, "", "")
+	b := NewCloud(w1, NewPosition(50, 50), NewVelocity(0, 0), 10, "", "")
+	w1.addCloud(a)
+	w1.addCloud(b)
+	if a.UID == b.UID {
+		t.Errorf("fail: uid %v", a.UID)
+	}
+}
+
+func TestWorld_Verify(t *testing.T) {
+	server := initTestWorld()
+	client := server.Clone()
+	server.Move(server.Me("Player 1"), NewVelocity(10, 5))
+	client.Move(client.Me("Player 1"), NewVelocity(10, 5))
+	for i := 0; i < 30; i++ {
+		server.Update()
+	}
+	iteration, _, _, _, _ := server.Stats()
+
+	// client catches up
+	if !client.Clone().Verify(iteration, server.Checksum()) {
+		t.Errorf("fail: equal worlds")
+	}
+
+	// prediction without the move
+	other := initTestWorld()
+	if other.Verify(iteration, server.Checksum()) {
+		t.Errorf("fail: different worlds")
+	}
+
+	// the client is ahead
+	for i := 0; i < 31; i++ {
+		client.Update()
+	}
+	if client.Verify(iteration, server.Checksum()) {
+		t.Errorf("fail: client ahead")
+	}
+}
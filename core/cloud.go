@@ -19,7 +19,9 @@ type Cloud struct {
 	Vapor  float32   // representing the amount of vapor in the cloud
 	Player string    // clouds controlled by a player
 	Color  string    // blue, red, orange, purple and gray (default: gray)
-	UID    string    // Unique Identifier (is set when the cloud is added to a world)
+	UID    string    // Unique Identifier (is set when the cloud is added to a world, see Checksum)
+
+	Effects []*Effect `json:",omitempty"` // active power-ups (see PowerUps)
 }
 
 // NewCloud create a new Cloud.
@@ -51,7 +53,7 @@ func (c *Cloud) IsDeath() bool {
 func (c *Cloud) isIntersects(o *Cloud) bool {
 	x := o.Pos.X - c.Pos.X
 	y := o.Pos.Y - c.Pos.Y
-	return float32(math.Sqrt(float64(x*x+y*y))) < (o.Radius() + c.Radius())
+	return float32(math.Sqrt(float64(float32(x*x)+float32(y*y)))) < (o.Radius() + c.Radius())
 }
 
 // clone creates a new instance of Position and initializes all its fields with exactly the contents.
@@ -59,6 +61,9 @@ func (c *Cloud) isIntersects(o *Cloud) bool {
 func (c *Cloud) clone() *Cloud {
 	ret := NewCloud(nil, c.Pos.clone(), c.Vel.clone(), c.Vapor, c.Player, c.Color)
 	ret.UID = c.UID // set by world.addCloud()
+	for _, e := range c.Effects {
+		ret.Effects = append(ret.Effects, &Effect{Kind: e.Kind, Ticks: e.Ticks})
+	}
 	return ret
 }
 
@@ -76,10 +81,38 @@ func (c *Cloud) update() {
 	// Movement
 	// position += velocity * 0.1;
 	simSpeedUp := 1
-	if c.world != nil && c.world.SimSpeedUp != 0 {
-		simSpeedUp = c.world.SimSpeedUp
+	if c.world != nil {
+		simSpeedUp = c.world.updateTicks()
+	}
+	c.tickEffects(simSpeedUp)
+	if c.HasEffect(ItemSpeed) {
+		c.Pos.add(c.Vel, 0.1*speedFactor*float32(simSpeedUp))
+	} else {
+		c.Pos.add(c.Vel, 0.1*float32(simSpeedUp))
+	}
+
+	// Power-ups (optional rule)
+	if c.world != nil {
+		c.pickUp()
+		if c.HasEffect(ItemMagnet) {
+			c.attract(simSpeedUp)
+		}
+	}
+
+	// Wind fields (optional rule)
+	// velocity += wind * 0.01;
+	if c.world != nil && len(c.world.rules.Wind) > 0 {
+		seconds := c.world.seconds()
+		for _, f := range c.world.rules.Wind {
+			wx, wy := f.At(c.Pos.X, c.Pos.Y, seconds)
+			c.Vel.add(NewVelocity(wx, wy), 0.01*float32(simSpeedUp))
+		}
+	}
+
+	// Evaporation (optional rule)
+	if c.world != nil {
+		c.evaporate(&c.world.rules.Weather, c.world.updateSeconds())
 	}
-	c.Pos.add(c.Vel, 0.1*float32(simSpeedUp))
 
 	// Damping of velocity
 	// velocity *= 0.999;
@@ -101,7 +134,13 @@ func (c *Cloud) update() {
 			biggest = c
 		}
 
+		// Shield (power-up) protects the smallest cloud
+		if smallest.HasEffect(ItemShield) {
+			continue
+		}
+
 		// Check for intersection
+		var amount float32
 		for c.isIntersects(o) {
 			if c.IsDeath() || o.IsDeath() {
 				break
@@ -109,30 +148,52 @@ func (c *Cloud) update() {
 			// Transfer vapor from smallest to biggest
 			biggest.Vapor += 1
 			smallest.Vapor -= 1
+			amount += 1
+		}
+
+		// events
+		if amount > 0 {
+			c.world.emit(Event{Kind: EventAbsorb, UID: biggest.UID, Player: biggest.Player, Other: smallest.UID, OtherPlayer: smallest.Player, Amount: amount})
+			if smallest.IsDeath() && smallest.Player != "" {
+				c.world.emit(Event{Kind: EventDeath, UID: smallest.UID, Player: smallest.Player, Other: biggest.UID, OtherPlayer: biggest.Player})
+			}
 		}
 	}
 
 	// Bounce against walls
+	bounce := false
 	if c.Pos.X < c.Radius() {
 		c.Pos.X = c.Radius()
 		c.Vel.X = float32(math.Abs(float64(c.Vel.X)) * 0.6)
+		bounce = true
 	}
 	if c.Pos.Y < c.Radius() {
 		c.Pos.Y = c.Radius()
 		c.Vel.Y = float32(math.Abs(float64(c.Vel.Y)) * 0.6)
+		bounce = true
 	}
 	if c.Pos.X+c.Radius() > float32(c.world.Width()) {
 		c.Pos.X = float32(c.world.Width()) - c.Radius()
 		c.Vel.X = float32(-math.Abs(float64(c.Vel.X)) * 0.6)
+		bounce = true
 	}
 	if c.Pos.Y+c.Radius() > float32(c.world.Height()) {
 		c.Pos.Y = float32(c.world.Height()) - c.Radius()
 		c.Vel.Y = float32(-math.Abs(float64(c.Vel.Y)) * 0.6)
+		bounce = true
+	}
+	if bounce {
+		c.world.emit(Event{Kind: EventBounce, UID: c.UID, Player: c.Player, X: c.Pos.X, Y: c.Pos.Y})
 	}
 }
 
 // move implements a move command. Vapor is reduced in order to generate Velocity.
 func (c *Cloud) move(wind *Velocity) bool {
+	return c.expel(wind, true)
+}
+
+// expel is the helper of move and kill (the explosion is no move event).
+func (c *Cloud) expel(wind *Velocity, event bool) bool {
 	// The Strength of the wind is calculated as sqrt(x*x+y*y)
 	strength := wind.Strength()
 
@@ -143,8 +204,23 @@ func (c *Cloud) move(wind *Velocity) bool {
 		return false
 	}
 
+	// Free move (power-up): no vapor costs and no exhaust gases
+	if c.HasEffect(ItemFree) {
+		if event {
+			c.world.emit(Event{Kind: EventMove, UID: c.UID, Player: c.Player, Amount: 0, X: wind.X, Y: wind.Y})
+		}
+		c.Vel.add(wind, 5/c.Radius())
+		if c.world.fixed() {
+			c.quantize()
+		}
+		return true
+	}
+
 	// The vapor property of the player controlled cloud will be reduced by Strength
 	c.Vapor -= strength
+	if event {
+		c.world.emit(Event{Kind: EventMove, UID: c.UID, Player: c.Player, Amount: strength, X: wind.X, Y: wind.Y})
+	}
 
 	// The vector [(x / Radius) * 5, (y / Radius) * 5] is added to the
 	// velocity of the cloud.
@@ -159,15 +235,23 @@ func (c *Cloud) move(wind *Velocity) bool {
 
 		// The position of the new cloud is set to
 		// [(int)(px - wx * distance), (int)(py - wy * distance)]
-		position := NewPosition(c.Pos.X-wind.X/strength*distance, c.Pos.Y-wind.Y/strength*distance)
+		position := NewPosition(c.Pos.X-float32(wind.X/strength*distance), c.Pos.Y-float32(wind.Y/strength*distance))
 
 		// with velocity
 		// [-(x / Strength) * 20 + vx, -(y / Strength) * 20 + vy]
-		velocity := NewVelocity(-(wind.X/strength)*20+c.Vel.X, -(wind.Y/strength)*20+c.Vel.Y)
+		velocity := NewVelocity(float32(-(wind.X/strength)*20)+c.Vel.X, float32(-(wind.Y/strength)*20)+c.Vel.Y)
 
 		// add to world
 		cloud := NewCloud(c.world, position, velocity, strength, "", "")
 		c.world.addCloud(cloud)
+		if c.world.fixed() {
+			cloud.quantize()
+		}
+	}
+
+	// fixed-point grid (optional rule)
+	if c.world.fixed() {
+		c.quantize()
 	}
 
 	// success
@@ -177,6 +261,9 @@ func (c *Cloud) move(wind *Velocity) bool {
 // kill is a suicide order. The cloud explodes.
 func (c *Cloud) kill() bool {
 	if c.IsDeath() {
+		if c.world != nil {
+			c.world.evaporated += c.Vapor // the remains are lost
+		}
 		c.Vapor = 0  // double kill ;)
 		return false // already dead
 	}
@@ -185,11 +272,14 @@ func (c *Cloud) kill() bool {
 	if c.world != nil {
 		for i := float32(0); i < 360 && !c.IsDeath(); i += 10 {
 			v := NewVelocityByAngle(i, 2.5)
-			c.move(v)
+			c.expel(v, false)
 		}
 	}
 
 	// kill
+	if c.world != nil {
+		c.world.evaporated += c.Vapor // the rest of the vapor is lost
+	}
 	c.Vapor = 0
 	return true // successful
 }
@@ -0,0 +1,114 @@
+package core
+
+// event kinds
+const (
+	EventSpawn  = "spawn"  // a new cloud (player, exhaust cloud, raincloud)
+	EventMove   = "move"   // move command executed (Amount: strength, X;Y: wind)
+	EventAbsorb = "absorb" // the cloud absorbed vapor from the other cloud (Amount: vapor)
+	EventDeath  = "death"  // a player cloud died (Other: killer; kill command: the cloud itself)
+	EventBounce = "bounce" // the cloud bounced off a wall (X;Y: position)
+	EventWinner = "winner" // the game is decided (Player: winner)
+	EventTick   = "tick"   // end of a world update step (not sent to remote clients)
+	EventReject = "reject" // move command rejected (X;Y: wind)
+)
+
+// Event is something that happened in the world.
+type Event struct {
+	Kind        string
+	Iteration   uint64  // world iteration when the event happened
+	UID         string  `json:",omitempty"` // cloud
+	Player      string  `json:",omitempty"` // player of the cloud
+	Other       string  `json:",omitempty"` // UID of the other cloud
+	OtherPlayer string  `json:",omitempty"` // player of the other cloud
+	Amount      float32 `json:",omitempty"`
+	X           float32 `json:",omitempty"`
+	Y           float32 `json:",omitempty"`
+}
+
+// listener is a registered event callback (see AddListener)
+type listener struct {
+	fn func(e Event)
+}
+
+// AddListener registers a callback for all events of this world. The events of a world update are delivered
+// after the update (outside the world lock), so the callback can use the world.
+// Clones don't copy the listeners. Resimulated iterations (see MoveAt) only emit the events that differ
+// from the first simulation (e.g. the death caused by a late move), events that no longer happen are not revoked.
+// Call remove to unregister the callback.
+func (w *World) AddListener(fn func(e Event)) (remove func()) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	l := &listener{fn: fn}
+	w.listeners = append(w.listeners, l)
+
+	return func() {
+		w.mux.Lock()
+		defer w.mux.Unlock()
+
+		for i, o := range w.listeners {
+			if o == l {
+				w.listeners = append(w.listeners[:i:i], w.listeners[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// emit adds an event for the listeners (not thread-safe).
+func (w *World) emit(e Event) {
+	if w == nil || len(w.listeners) == 0 {
+		return
+	}
+	e.Iteration = w.iteration
+	if w.replaying {
+		w.replay = append(w.replay, e)
+		return
+	}
+	w.events = append(w.events, e)
+
+	// events of the history window (see MoveAt)
+	if len(w.history) > 0 && e.Kind != EventTick {
+		n := 0
+		for n < len(w.past) && w.past[n].Iteration+uint64(len(w.history)) < w.iteration {
+			n++
+		}
+		w.past = append(w.past[n:], e)
+	}
+}
+
+// replayed emits the events of a resimulation that were not emitted in the first simulation (not thread-safe).
+func (w *World) replayed() {
+	emitted := make(map[Event]int, len(w.past))
+	for _, e := range w.past {
+		emitted[e]++
+	}
+	replay := w.replay
+	w.replay = nil
+	for _, e := range replay {
+		if e.Kind == EventTick {
+			continue
+		}
+		if emitted[e] > 0 {
+			emitted[e]-- // already emitted
+			continue
+		}
+		w.events = append(w.events, e)
+		w.past = append(w.past, e)
+	}
+}
+
+// dispatch delivers all new events to the listeners (call without lock).
+func (w *World) dispatch() {
+	w.mux.Lock()
+	events := w.events
+	listeners := w.listeners
+	w.events = nil
+	w.mux.Unlock()
+
+	for _, e := range events {
+		for _, l := range listeners {
+			l.fn(e)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestWorld_AddListener(t *testing.T) {
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w.addCloud(NewCloud(w, NewPosition(150, 850), NewVelocity(0, 0), 300, "", "")) // no winner at the start
+	var events []Event
+	var ticks int
+	remove := w.AddListener(func(e Event) {
+		if e.Kind == EventTick {
+			ticks++
+			return
+		}
+		events = append(events, e)
+		w.Clouds() // no deadlock
+	})
+	kinds := func() map[string]int {
+		m := make(map[string]int)
+		for _, e := range events {
+			m[e.Kind]++
+		}
+		return m
+	}
+
+	// spawn
+	p1 := w.AddPlayer("Player 1", "red", NewPosition(300, 500), 100)
+	p2 := w.AddPlayer("Player 2", "blue", NewPosition(700, 500), 400)
+	w.Update()
+	if k := kinds(); k[EventSpawn] != 2 || len(events) != 2 || ticks != 1 {
+		t.Fatalf("fail: %v", events)
+	}
+
+	// move with exhaust cloud
+	events = nil
+	w.Move(p2, NewVelocity(-20, 0))
+	if len(events) != 2 || events[0].Kind != EventMove || events[0].Amount != 20 || events[1].Kind != EventSpawn || events[1].Iteration != 1 {
+		t.Fatalf("fail: %v", events)
+	}
+
+	// invalid move
+	events = nil
+	w.Move(p2, NewVelocity(1000, 0))
+	if len(events) != 1 || events[0].Kind != EventReject || events[0].X != 1000 {
+		t.Fatalf("fail: %v", events)
+	}
+
+	// absorb, death and winner
+	events = nil
+	p1.Pos.X = p2.Pos.X - 10
+	w.Update()
+	k := kinds()
+	if k[EventAbsorb] == 0 || k[EventDeath] != 1 || k[EventWinner] != 1 {
+		t.Fatalf("fail: %v", events)
+	}
+	for _, e := range events {
+		if e.Kind == EventDeath && (e.UID != p1.UID || e.OtherPlayer != "Player 2") {
+			t.Errorf("fail: %v", e)
+		}
+		if e.Kind == EventWinner && e.Player != "Player 2" {
+			t.Errorf("fail: %v", e)
+		}
+	}
+
+	// bounce
+	events = nil
+	p2.Pos.X = 5
+	w.Update()
+	if k := kinds(); k[EventBounce] != 1 {
+		t.Errorf("fail: %v", events)
+	}
+
+	// kill (suicide) and remove listener
+	events = nil
+	w.Kill(p2)
+	if k := kinds(); k[EventSpawn] == 0 || k[EventMove] != 0 || events[len(events)-1].Kind != EventDeath || events[len(events)-1].Other != p2.UID {
+		t.Errorf("fail: %v", events)
+	}
+	remove()
+	events = nil
+	w.AddPlayer("Player 3", "red", NewPosition(500, 500), 100)
+	if len(events) != 0 || w.Clone().listeners != nil {
+		t.Errorf("fail: %v", events)
+	}
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden trajectories in testdata/golden")
+
+// golden is a trajectory file (see README: Golden trajectories).
+type golden struct {
+	Description string
+	World       json.RawMessage    // initial world (like the 'list' command)
+	Commands    []goldenCommand    // player commands
+	Checkpoints []goldenCheckpoint // expected clouds after some iterations
+}
+
+// goldenCommand is executed before the update of the given iteration.
+type goldenCommand struct {
+	Iteration uint64
+	Player    string
+	Kill      bool    `json:",omitempty"`
+	X         float32 `json:",omitempty"`
+	Y         float32 `json:",omitempty"`
+}
+
+// goldenCheckpoint contains all clouds (in world order) at the given iteration.
+type goldenCheckpoint struct {
+	Iteration uint64
+	Checksum  string // see World.Checksum
+	Clouds    []goldenCloud
+}
+
+type goldenCloud struct {
+	Player string `json:",omitempty"`
+	Vapor  float32
+	Pos    Position
+	Vel    Velocity
+}
+
+// goldenScenarios returns the initial world and the commands of all golden trajectories.
+func goldenScenarios() map[string]golden {
+	newWorld := func(rules Rules) *World {
+		w := NewWorld(1200, 800, 60, 30, 30, 300, 42)
+		w.SetRules(rules)
+		w.AddPlayer("red", "red", NewPosition(200, 200), 400)
+		w.AddPlayer("blue", "blue", NewPosition(1000, 600), 400)
+		return w
+	}
+	commands := []goldenCommand{
+		{Iteration: 10, Player: "red", X: 12, Y: -5},
+		{Iteration: 10, Player: "blue", X: -8, Y: 3.5},
+		{Iteration: 90, Player: "red", X: -3, Y: 9},
+		{Iteration: 200, Player: "blue", X: 15, Y: 15},
+		{Iteration: 350, Player: "red", X: 7.25, Y: 0.5},
+		{Iteration: 500, Player: "blue", Kill: true},
+	}
+	rules := Rules{
+		Wind:     []*WindField{NewDriftWind(0.5, -0.25), NewVortexWind(600, 400, 3, 150), NewGustWind(1, 0.5, 4)},
+		Weather:  Weather{EvaporationRate: 0.5, EvaporationSize: 20, RainInterval: 1, RainMinVapor: 10, RainMaxVapor: 60, RainMaxSpeed: 5, Seed: 7},
+		PowerUps: PowerUps{Interval: 2, Duration: 3, Max: 4, Seed: 11},
+		Gravity:  Gravity{Constant: 0.5, Cutoff: 250},
+	}
+	fixed := rules
+	fixed.Precision = PrecisionFixed
+
+	return map[string]golden{
+		"classic": {
+			Description: "classic game without optional rules",
+			World:       json.RawMessage(newWorld(Rules{}).ToJson()),
+			Commands:    commands,
+		},
+		"rules": {
+			Description: "wind, evaporation, rain, power-ups and gravity",
+			World:       json.RawMessage(newWorld(rules).ToJson()),
+			Commands:    commands,
+		},
+		"fixed": {
+			Description: "all optional rules with fixed-point precision",
+			World:       json.RawMessage(newWorld(fixed).ToJson()),
+			Commands:    commands,
+		},
+	}
+}
+
+// runGolden replays the commands and calls fn after each update.
+func runGolden(g golden, last uint64, fn func(w *World)) {
+	w := new(World)
+	w.FromJson(string(g.World))
+	for w.iteration < last {
+		for _, cmd := range g.Commands {
+			if cmd.Iteration != w.iteration {
+				continue
+			}
+			if cmd.Kill {
+				w.Kill(w.Me(cmd.Player))
+			} else {
+				w.Move(w.Me(cmd.Player), NewVelocity(cmd.X, cmd.Y))
+			}
+		}
+		w.Update()
+		fn(w)
+	}
+}
+
+// goldenClouds returns the clouds without UIDs (see Checksum).
+func goldenClouds(w *World) []goldenCloud {
+	list := make([]goldenCloud, 0, len(w.clouds))
+	for _, c := range w.clouds {
+		list = append(list, goldenCloud{Player: c.Player, Vapor: c.Vapor, Pos: *c.Pos, Vel: *c.Vel})
+	}
+	return list
+}
+
+func TestGoldenTrajectories(t *testing.T) {
+	dir := filepath.Join("testdata", "golden")
+
+	// go test ./core -run Golden -update
+	if *updateGolden {
+		for name, g := range goldenScenarios() {
+			runGolden(g, 600, func(w *World) {
+				if w.iteration%60 == 0 {
+					g.Checkpoints = append(g.Checkpoints, goldenCheckpoint{Iteration: w.iteration, Checksum: fmt.Sprintf("%016x", w.checksum()), Clouds: goldenClouds(w)})
+				}
+			})
+			b, err := json.MarshalIndent(g, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name+".json"), append(b, '\n'), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) == 0 {
+		t.Fatalf("fail: no golden trajectories in %s", dir)
+	}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var g golden
+		if err := json.Unmarshal(b, &g); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		// replay and compare bit by bit
+		expected := make(map[uint64]goldenCheckpoint)
+		var last uint64
+		for _, cp := range g.Checkpoints {
+			expected[cp.Iteration] = cp
+			last = cp.Iteration
+		}
+		runGolden(g, last, func(w *World) {
+			cp, ok := expected[w.iteration]
+			if !ok {
+				return
+			}
+			if sum := fmt.Sprintf("%016x", w.checksum()); sum != cp.Checksum {
+				t.Errorf("%s: iteration %d: checksum %s, expected %s", file, w.iteration, sum, cp.Checksum)
+			}
+			got, exp := goldenClouds(w), cp.Clouds
+			if len(got) != len(exp) {
+				t.Fatalf("%s: iteration %d: %d clouds, expected %d", file, w.iteration, len(got), len(exp))
+			}
+			for i := range got {
+				if !sameGoldenCloud(got[i], exp[i]) {
+					t.Fatalf("%s: iteration %d: cloud %d: %+v, expected %+v", file, w.iteration, i, got[i], exp[i])
+				}
+			}
+		})
+	}
+}
+
+func sameGoldenCloud(a, b goldenCloud) bool {
+	bits := math.Float32bits
+	return a.Player == b.Player && bits(a.Vapor) == bits(b.Vapor) &&
+		bits(a.Pos.X) == bits(b.Pos.X) && bits(a.Pos.Y) == bits(b.Pos.Y) &&
+		bits(a.Vel.X) == bits(b.Vel.X) && bits(a.Vel.Y) == bits(b.Vel.Y)
+}
+
+func TestQuantize(t *testing.T) {
+	tests := []struct{ in, out float32 }{
+		{0, 0},
+		{1.5, 1.5},
+		{1.001, 1},
+		{1.002, 1.00390625},
+		{-1.002, -1.00390625},
+		{1.0 / 512, 1.0 / 256}, // halfway: away from zero
+		{65535.996, 65535.996},
+	}
+	for _, tt := range tests {
+		if got := quantize(tt.in); got != tt.out {
+			t.Errorf("fail: %v -> %v, expected %v", tt.in, got, tt.out)
+		}
+	}
+
+	// the fixed world stays on the grid
+	w := NewWorld(1200, 800, 60, 30, 30, 300, 42)
+	w.SetRules(Rules{Precision: PrecisionFixed})
+	w.Move(w.AddPlayer("red", "red", NewPosition(200, 200), 400), NewVelocity(3.3, 1.1))
+	for i := 0; i < 10; i++ {
+		w.Update()
+	}
+	for _, c := range w.Clouds() {
+		for _, v := range []float32{c.Pos.X, c.Pos.Y, c.Vel.X, c.Vel.Y, c.Vapor} {
+			if v != quantize(v) {
+				t.Errorf("fail: %v", v)
+			}
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	// reference values of SplitMix64 (seed 0)
+	r := newRandom(0, 0)
+	for _, exp := range []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4, 0x06c45d188009454f} {
+		if got := r.next(); got != exp {
+			t.Errorf("fail: %x != %x", got, exp)
+		}
+	}
+
+	// range
+	r = newRandom(1337, 99)
+	for i := 0; i < 1000; i++ {
+		if f := r.float32(); f < 0 || f >= 1 {
+			t.Errorf("fail: %v", f)
+		}
+		if n := r.intn(3); n < 0 || n >= 3 {
+			t.Errorf("fail: %v", n)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"math"
+)
+
+// Gravity contains the optional rule for the attraction between clouds.
+// Every cloud is attracted to all larger clouds within the cutoff radius.
+// The velocity change per tick is Constant * vapor / distance² (vapor of the larger cloud).
+// The zero value disables gravity.
+type Gravity struct {
+	Constant float32 // gravitational constant (0 = off)
+	Cutoff   float32 // max distance between the centers of two clouds
+}
+
+// gravity changes the velocity of all clouds (not thread-safe).
+// ticks is the duration of the update in game ticks (see SimSpeedUp).
+func (w *World) gravity(ticks int) {
+	g := w.rules.Gravity
+	if g.Constant == 0 || g.Cutoff <= 0 {
+		return // no gravity
+	}
+
+	// calculate all accelerations first, so that the order of the clouds doesn't matter
+	w.grid = w.grid.reset(w.clouds, g.Cutoff, w.width, w.height)
+	grid := w.grid
+	acc := make([]Velocity, len(w.clouds))
+	for i, c := range w.clouds {
+		if c.IsDeath() {
+			continue
+		}
+		grid.near(c.Pos.X, c.Pos.Y, g.Cutoff, func(o *Cloud) {
+			ax, ay := c.attraction(o, g)
+			acc[i].X += ax
+			acc[i].Y += ay
+		})
+	}
+
+	// change velocity
+	for i, c := range w.clouds {
+		c.Vel.add(&acc[i], float32(ticks))
+	}
+}
+
+// attraction returns the velocity change per tick caused by the other cloud.
+// Only larger clouds within the cutoff radius attract.
+func (c *Cloud) attraction(o *Cloud, g Gravity) (ax, ay float32) {
+	if o == c || o.Vapor <= c.Vapor || o.IsDeath() {
+		return 0, 0
+	}
+
+	x := o.Pos.X - c.Pos.X
+	y := o.Pos.Y - c.Pos.Y
+	d := float32(math.Sqrt(float64(float32(x*x) + float32(y*y))))
+	if d == 0 || d > g.Cutoff {
+		return 0, 0
+	}
+
+	// overlapping clouds: limit the force
+	r := d
+	if minD := c.Radius() + o.Radius(); r < minD {
+		r = minD
+	}
+
+	a := g.Constant * o.Vapor / (r * r)
+	return float32(x / d * a), float32(y / d * a)
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"testing"
+)
+
+// gravityBruteForce is the reference implementation without spatial index.
+func gravityBruteForce(w *World) {
+	g := w.rules.Gravity
+	acc := make([]Velocity, len(w.clouds))
+	for i, c := range w.clouds {
+		if c.IsDeath() {
+			continue
+		}
+		for _, o := range w.clouds {
+			ax, ay := c.attraction(o, g)
+			acc[i].X += ax
+			acc[i].Y += ay
+		}
+	}
+	for i, c := range w.clouds {
+		c.Vel.add(&acc[i], 1)
+	}
+}
+
+func initGravityWorld(amount int, cutoff float32) *World {
+	w := NewWorld(6666, 3333, 60, amount, 10, 800, 1337)
+	w.SetRules(Rules{Gravity: Gravity{Constant: 1, Cutoff: cutoff}})
+	return w
+}
+
+func TestCloud_attraction(t *testing.T) {
+	g := Gravity{Constant: 2, Cutoff: 500}
+	small := NewCloud(nil, NewPosition(100, 100), NewVelocity(0, 0), 10, "", "")
+	big := NewCloud(nil, NewPosition(300, 100), NewVelocity(0, 0), 400, "", "")
+
+	// only larger clouds attract: 2 * 400 / 200²
+	if ax, ay := small.attraction(big, g); !almostEqual(ax, 0.02) || ay != 0 {
+		t.Errorf("fail: %v %v", ax, ay)
+	}
+	if ax, ay := big.attraction(small, g); ax != 0 || ay != 0 {
+		t.Errorf("fail: %v %v", ax, ay)
+	}
+
+	// cutoff
+	big.Pos.X = 700
+	if ax, ay := small.attraction(big, g); ax != 0 || ay != 0 {
+		t.Errorf("fail: %v %v", ax, ay)
+	}
+
+	// overlapping clouds: distance is at least the sum of the radii (sqrt(10) + 20)
+	big.Pos.X = 105
+	if ax, _ := small.attraction(big, g); !almostEqual(ax, 2*400/(23.162278*23.162278)) {
+		t.Errorf("fail: %v", ax)
+	}
+}
+
+func TestWorld_gravity(t *testing.T) {
+	for _, cutoff := range []float32{0.1, 50, 300, 10000} {
+		w1 := initGravityWorld(500, cutoff)
+		w2 := w1.Clone()
+		w1.gravity(1)
+		gravityBruteForce(w2)
+
+		// the spatial index finds the same clouds
+		c1, c2 := w1.Clouds(), w2.Clouds()
+		for i := range c1 {
+			if !almostEqual(c1[i].Vel.X, c2[i].Vel.X) || !almostEqual(c1[i].Vel.Y, c2[i].Vel.Y) {
+				t.Errorf("cutoff %v: cloud %d: %v != %v", cutoff, i, c1[i].Vel, c2[i].Vel)
+			}
+		}
+	}
+
+	// small cutoff: the cells are limited and reused
+	w1 := initGravityWorld(500, 0.1)
+	w1.gravity(1)
+	grid := w1.grid
+	w1.gravity(1)
+	if grid.cellSize != minCellSize || len(grid.cells) != grid.cols*grid.rows || w1.grid != grid {
+		t.Errorf("fail: %v %d", grid.cellSize, len(grid.cells))
+	}
+
+	// gravity is applied in Update
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w.SetRules(Rules{Gravity: Gravity{Constant: 1, Cutoff: 500}})
+	small := NewCloud(w, NewPosition(100, 100), NewVelocity(0, 0), 10, "", "")
+	w.addCloud(small)
+	w.addCloud(NewCloud(w, NewPosition(400, 100), NewVelocity(0, 0), 900, "", ""))
+	w.Update()
+	if small.Vel.X <= 0 || small.Vel.Y != 0 || small.Pos.X <= 100 { // before movement
+		t.Errorf("fail: %v %v", small.Vel, small.Pos)
+	}
+}
+
+func BenchmarkWorld_gravity(b *testing.B) {
+	w := initGravityWorld(2000, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.gravity(1)
+	}
+}
+
+func BenchmarkWorld_gravityBruteForce(b *testing.B) {
+	w := initGravityWorld(2000, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gravityBruteForce(w)
+	}
+}
+
+func BenchmarkWorld_Update(b *testing.B) {
+	w := initGravityWorld(500, 0) // classic game
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Update()
+	}
+}
+
+func BenchmarkWorld_UpdateGravity(b *testing.B) {
+	w := initGravityWorld(500, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Update()
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+// spatialGrid is a spatial index for clouds.
+// The game board is divided into square cells and each cloud is sorted into the cell of its center.
+// A query only checks the cells within range instead of all clouds.
+type spatialGrid struct {
+	cellSize float32
+	cols     int
+	rows     int
+	cells    [][]*Cloud
+}
+
+// minCellSize limits the number of cells for small query radii.
+const minCellSize = 32
+
+// reset sorts all living clouds into the grid again and returns it.
+// The cell size is at least minCellSize, the cells are reused if the dimensions don't change (g can be nil).
+func (g *spatialGrid) reset(clouds []*Cloud, cellSize float32, width, height int) *spatialGrid {
+	if cellSize < minCellSize {
+		cellSize = minCellSize
+	}
+	cols := int(float32(width)/cellSize) + 1
+	rows := int(float32(height)/cellSize) + 1
+	if g == nil || g.cellSize != cellSize || g.cols != cols || g.rows != rows {
+		g = &spatialGrid{cellSize: cellSize, cols: cols, rows: rows, cells: make([][]*Cloud, cols*rows)}
+	} else {
+		for i := range g.cells {
+			g.cells[i] = g.cells[i][:0]
+		}
+	}
+
+	for _, c := range clouds {
+		if !c.IsDeath() {
+			i := g.index(g.cell(c.Pos.X, g.cols), g.cell(c.Pos.Y, g.rows))
+			g.cells[i] = append(g.cells[i], c)
+		}
+	}
+	return g
+}
+
+// near calls fn for each cloud in the cells within the radius around x;y.
+// The callback can also receive clouds that are slightly outside the radius.
+func (g *spatialGrid) near(x, y, radius float32, fn func(o *Cloud)) {
+	col0, col1 := g.cell(x-radius, g.cols), g.cell(x+radius, g.cols)
+	row0, row1 := g.cell(y-radius, g.rows), g.cell(y+radius, g.rows)
+	for row := row0; row <= row1; row++ {
+		for col := col0; col <= col1; col++ {
+			for _, o := range g.cells[g.index(col, row)] {
+				fn(o)
+			}
+		}
+	}
+}
+
+// cell returns the column or row of a coordinate
+func (g *spatialGrid) cell(v float32, length int) int {
+	return clampIndex(int(v/g.cellSize), length)
+}
+
+// index returns the position of a cell in the cell list
+func (g *spatialGrid) index(col, row int) int {
+	return row*g.cols + col
+}
@@ -0,0 +1,259 @@
+package core
+
+import (
+	"errors"
+)
+
+// EnableHistory keeps snapshots of the last n iterations in a ring buffer (n <= 0: off).
+// A snapshot is taken at the beginning of each update, so it contains all moves of its iteration.
+// Old snapshots are removed.
+func (w *World) EnableHistory(n int) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if n <= 0 {
+		w.history = nil
+	} else {
+		w.history = make([]*World, n)
+	}
+	w.commands = nil
+}
+
+// History returns the size of the history (0: off).
+func (w *World) History() int {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return len(w.history)
+}
+
+// At returns a clone of the world at the given iteration.
+// Returns nil if the iteration is not (or no longer) in the history.
+func (w *World) At(iteration uint64) *World {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if iteration == w.iteration {
+		return w.clone()
+	}
+	if s := w.snapshot(iteration); s != nil {
+		return s.clone()
+	}
+	return nil
+}
+
+// Rewind resets the world to the given iteration.
+// All clouds that also exist in the snapshot keep their instances (e.g. the reference to a player cloud),
+// clouds created after the snapshot are removed.
+// Returns false if the iteration is not (or no longer) in the history.
+func (w *World) Rewind(iteration uint64) bool {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if iteration == w.iteration {
+		return true
+	}
+	s := w.snapshot(iteration)
+	if s == nil {
+		return false
+	}
+	w.restore(s)
+
+	// the commands of the following iterations are discarded
+	// (the commands of this iteration are already in the snapshot)
+	for i, cmd := range w.commands {
+		if cmd.iteration > iteration {
+			w.commands = w.commands[:i]
+			break
+		}
+	}
+	return true
+}
+
+// record adds a snapshot of the current iteration to the history (not thread-safe).
+func (w *World) record() {
+	if len(w.history) > 0 {
+		w.history[w.iteration%uint64(len(w.history))] = w.clone()
+	}
+}
+
+// snapshot returns the stored snapshot of a past iteration or nil (not thread-safe).
+func (w *World) snapshot(iteration uint64) *World {
+	if len(w.history) == 0 || iteration > w.iteration {
+		return nil
+	}
+	s := w.history[iteration%uint64(len(w.history))]
+	if s == nil || s.iteration != iteration {
+		return nil
+	}
+	return s
+}
+
+// restore overrides the world state with a snapshot (not thread-safe).
+// SimSpeedUp, SubStepping, freeze and the history are not changed.
+func (w *World) restore(s *World) {
+	// keep cloud instances
+	live := make(map[string]*Cloud, len(w.clouds))
+	for _, c := range w.clouds {
+		live[c.UID] = c
+	}
+	clouds := make([]*Cloud, 0, len(s.clouds))
+	for _, sc := range s.clouds {
+		c := sc.clone()
+		if o, ok := live[c.UID]; ok {
+			*o = *c
+			c = o
+		}
+		c.world = w
+		clouds = append(clouds, c)
+	}
+	w.clouds = clouds
+
+	// items
+	w.items = nil
+	for _, i := range s.items {
+		w.items = append(w.items, i.clone())
+	}
+
+	// state
+	w.width = s.width
+	w.height = s.height
+	w.gameSpeed = s.gameSpeed
+	w.iteration = s.iteration
+	w.worldVapor = s.worldVapor
+	w.alive = s.alive
+	w.winCondition = s.winCondition
+	w.leader = s.leader
+	w.evaporated = s.evaporated
+	w.rained = s.rained
+	w.rules = s.rules
+	w.rainTimer = s.rainTimer
+	w.spawned = s.spawned
+	w.itemTimer = s.itemTimer
+}
+
+//----  LAG COMPENSATION  --------------------------------------------------------------------------------------------//
+
+// errors of MoveAt
+var (
+	ErrInvalidMove     = errors.New("invalid move")
+	ErrStaleMove       = errors.New("stale move")
+	ErrFutureIteration = errors.New("invalid iteration")
+)
+
+// command is a move or kill command in the history.
+type command struct {
+	iteration uint64
+	uid       string
+	wind      *Velocity // nil: kill
+}
+
+// MoveAt executes a move command that is based on an older iteration (lag compensation).
+// The world is rewound to this iteration, the move is executed and all following iterations are simulated
+// again with the recorded commands of all clouds. Returns the iteration in which the move was executed.
+//
+// Without history (see EnableHistory), the move is executed in the current iteration.
+// Iterations that are no longer in the history return ErrStaleMove.
+func (w *World) MoveAt(c *Cloud, wind *Velocity, iteration uint64) (uint64, error) {
+	defer w.dispatch()
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	current := w.iteration
+	if iteration > current {
+		return current, ErrFutureIteration
+	}
+	if c == nil || c.world == nil || w.freeze || wind == nil {
+		return current, ErrInvalidMove
+	}
+
+	// no lag compensation
+	if len(w.history) == 0 || iteration == current {
+		if !c.move(wind) {
+			w.emit(Event{Kind: EventReject, UID: c.UID, Player: c.Player, X: wind.X, Y: wind.Y})
+			return current, ErrInvalidMove
+		}
+		w.logCommand(current, c.UID, wind.clone())
+		return current, nil
+	}
+
+	// rewind
+	s := w.snapshot(iteration)
+	if s == nil {
+		return current, ErrStaleMove
+	}
+	w.restore(s)
+
+	// move
+	err := ErrInvalidMove
+	if me := w.cloud(c.UID); me != nil && me.move(wind) {
+		w.logCommand(iteration, c.UID, wind.clone())
+		err = nil
+	}
+
+	// simulate again (only the changed events are emitted)
+	w.replaying = true
+	for w.iteration < current {
+		w.update()
+		for _, cmd := range w.commands {
+			if cmd.iteration == w.iteration {
+				cmd.execute(w)
+			}
+		}
+	}
+	w.replaying = false
+	w.replayed()
+
+	if err != nil {
+		w.emit(Event{Kind: EventReject, UID: c.UID, Player: c.Player, X: wind.X, Y: wind.Y})
+		return current, err
+	}
+	return iteration, nil
+}
+
+// logCommand adds a command to the history (not thread-safe).
+// Commands that are older than the history are removed.
+func (w *World) logCommand(iteration uint64, uid string, wind *Velocity) {
+	if len(w.history) == 0 {
+		return
+	}
+
+	// remove old commands
+	n := 0
+	for n < len(w.commands) && w.commands[n].iteration+uint64(len(w.history)) < w.iteration {
+		n++
+	}
+	w.commands = w.commands[n:]
+
+	// insert (sorted by iteration)
+	i := len(w.commands)
+	for i > 0 && w.commands[i-1].iteration > iteration {
+		i--
+	}
+	w.commands = append(w.commands, nil)
+	copy(w.commands[i+1:], w.commands[i:])
+	w.commands[i] = &command{iteration: iteration, uid: uid, wind: wind}
+}
+
+// execute the recorded command again (not thread-safe).
+func (cmd *command) execute(w *World) {
+	c := w.cloud(cmd.uid)
+	if c == nil {
+		return
+	}
+	if cmd.wind == nil {
+		c.kill()
+	} else {
+		c.move(cmd.wind)
+	}
+}
+
+// cloud returns the cloud with the given UID or nil (not thread-safe).
+func (w *World) cloud(uid string) *Cloud {
+	for _, c := range w.clouds {
+		if c.UID == uid {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestWorld_History(t *testing.T) {
+	ref := initTestWorld()
+	w := ref.Clone()
+	w.EnableHistory(30)
+	me := w.Me("Player 1")
+
+	// reference: 20 and 50 iterations with a move in iteration 10
+	var sum20, sum50 uint64
+	for i := 0; i < 50; i++ {
+		if i == 10 {
+			ref.Move(ref.Me("Player 1"), NewVelocity(10, 5))
+			w.Move(me, NewVelocity(10, 5))
+		}
+		if i == 20 {
+			sum20 = ref.Checksum()
+		}
+		ref.Update()
+		w.Update()
+	}
+	sum50 = ref.Checksum()
+
+	// At
+	if a := w.At(20); a == nil || a.Checksum() != sum20 || a.History() != 0 {
+		t.Errorf("fail: At(20)")
+	}
+	if a := w.At(50); a == nil || a.Checksum() != sum50 {
+		t.Errorf("fail: At(50)")
+	}
+	if w.At(10) != nil || w.At(51) != nil {
+		t.Errorf("fail: iteration not in history")
+	}
+
+	// Rewind and replay
+	if !w.Rewind(20) || w.Checksum() != sum20 {
+		t.Fatalf("fail: Rewind(20)")
+	}
+	if w.Me("Player 1") != me || w.At(21) != nil {
+		t.Errorf("fail: instance or future")
+	}
+	for i := 0; i < 30; i++ {
+		w.Update()
+	}
+	if w.Checksum() != sum50 {
+		t.Errorf("fail: replay")
+	}
+	if w.Rewind(5) || w.Clone().History() != 0 {
+		t.Errorf("fail: Rewind(5)")
+	}
+}
+
+func TestWorld_MoveAt(t *testing.T) {
+	ref := initTestWorld()
+	w := ref.Clone()
+	w.EnableHistory(30)
+	me := w.Me("Player 1")
+
+	// reference: Player 1 moves in iteration 10, Player 2 in 12 and 15
+	for i := 0; i < 20; i++ {
+		if i == 10 {
+			ref.Move(ref.Me("Player 1"), NewVelocity(10, 5))
+		}
+		if i == 12 || i == 15 {
+			ref.Move(ref.Me("Player 2"), NewVelocity(-3, 8))
+			w.Move(w.Me("Player 2"), NewVelocity(-3, 8))
+		}
+		ref.Update()
+		w.Update()
+	}
+	ref.Move(ref.Me("Player 2"), NewVelocity(1, 1))
+	w.Move(w.Me("Player 2"), NewVelocity(1, 1)) // current iteration
+
+	// late move (based on iteration 10)
+	if i, err := w.MoveAt(me, NewVelocity(10, 5), 10); i != 10 || err != nil {
+		t.Fatalf("fail: %v %v", i, err)
+	}
+	if w.Checksum() != ref.Checksum() || w.Me("Player 1") != me {
+		t.Errorf("fail: resimulation")
+	}
+
+	// errors
+	if _, err := w.MoveAt(me, NewVelocity(10, 5), 21); err != ErrFutureIteration {
+		t.Errorf("fail: %v", err)
+	}
+	if _, err := w.MoveAt(me, NewVelocity(10000, 5), 15); err != ErrInvalidMove || w.Checksum() != ref.Checksum() {
+		t.Errorf("fail: %v", err)
+	}
+	for i := 0; i < 30; i++ {
+		w.Update()
+	}
+	if _, err := w.MoveAt(me, NewVelocity(10, 5), 15); err != ErrStaleMove {
+		t.Errorf("fail: %v", err)
+	}
+
+	// without history: current iteration
+	w = initTestWorld()
+	w.Update()
+	if i, err := w.MoveAt(w.Me("Player 1"), NewVelocity(10, 5), 0); i != 1 || err != nil {
+		t.Errorf("fail: %v %v", i, err)
+	}
+}
+
+func TestWorld_MoveAtEvents(t *testing.T) {
+	// a small enemy 8px in front of Player 1 (the kill decides the game)
+	w := NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	w.EnableHistory(120)
+	me := w.AddPlayer("Player 1", "red", NewPosition(300, 300), 600)
+	w.AddPlayer("Player 2", "blue", NewPosition(342.5, 300), 100)
+	w.AddPlayer("Player 3", "gray", NewPosition(800, 300), 600)
+	var events []Event
+	w.AddListener(func(e Event) {
+		if e.Kind != EventTick {
+			events = append(events, e)
+		}
+	})
+	for i := 0; i < 100; i++ {
+		w.Update()
+	}
+	if len(events) != 0 {
+		t.Fatalf("fail: %+v", events)
+	}
+
+	// late move: the kill and the end of the game happened in the past
+	if _, err := w.MoveAt(me, NewVelocity(20, 0), 5); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	kinds := make(map[string]int)
+	for _, e := range events {
+		kinds[e.Kind]++
+		if e.Iteration < 5 || e.Iteration > 100 {
+			t.Errorf("fail: %+v", e)
+		}
+	}
+	if kinds[EventMove] != 1 || kinds[EventSpawn] != 1 || kinds[EventAbsorb] == 0 || kinds[EventDeath] != 1 || kinds[EventWinner] != 1 {
+		t.Errorf("fail: %v", kinds)
+	}
+
+	// the kill and the winner are not emitted again (only the new move and its exhaust cloud)
+	events = nil
+	if _, err := w.MoveAt(me, NewVelocity(0, 3), 95); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if len(events) != 2 || events[0].Kind != EventMove || events[1].Kind != EventSpawn {
+		t.Errorf("fail: %+v", events)
+	}
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"math"
+)
+
+// item kinds
+const (
+	ItemSpeed  = "speed"  // the cloud moves twice as fast
+	ItemShield = "shield" // the cloud does not lose vapor to other clouds
+	ItemFree   = "free"   // move commands cost no vapor (and spawn no exhaust clouds)
+	ItemMagnet = "magnet" // neutral clouds nearby are attracted
+)
+
+// item constants
+const (
+	ItemRadius  = 12  // pickup radius of an item
+	speedFactor = 2   // speed: movement multiplier
+	magnetRange = 300 // magnet: range of the attraction
+	magnetForce = 0.1 // magnet: velocity change per tick
+)
+
+// PowerUps contains the optional rules for collectible items.
+// Items spawn at random positions and are picked up by player clouds on contact.
+// The zero value disables power-ups.
+type PowerUps struct {
+	Interval float32  // seconds between two new items (0 = off)
+	Duration float32  // seconds an effect lasts
+	Max      int      // max number of items on the game board (0 = unlimited)
+	Kinds    []string // possible item kinds (DEFAULT: all)
+	Seed     int64    // random seed for items
+}
+
+// Item is a collectible power-up on the game board.
+type Item struct {
+	Kind string    // speed, shield, free or magnet
+	Pos  *Position // position
+	UID  string    // Unique Identifier
+}
+
+// Effect is a timed power-up effect of a cloud.
+type Effect struct {
+	Kind  string // speed, shield, free or magnet
+	Ticks int    // remaining game ticks
+}
+
+// clone creates a new instance of Item and initializes all its fields with exactly the contents.
+func (i *Item) clone() *Item {
+	return &Item{Kind: i.Kind, Pos: i.Pos.clone(), UID: i.UID}
+}
+
+//----  Cloud  -------------------------------------------------------------------------------------------------------//
+
+// HasEffect is true if the cloud has an active power-up effect of this kind.
+func (c *Cloud) HasEffect(kind string) bool {
+	for _, e := range c.Effects {
+		if e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// addEffect adds or extends a power-up effect.
+func (c *Cloud) addEffect(kind string, ticks int) {
+	for _, e := range c.Effects {
+		if e.Kind == kind {
+			e.Ticks = ticks // restart
+			return
+		}
+	}
+	c.Effects = append(c.Effects, &Effect{Kind: kind, Ticks: ticks})
+}
+
+// tickEffects counts down the effects and removes expired effects.
+func (c *Cloud) tickEffects(ticks int) {
+	if len(c.Effects) == 0 {
+		return
+	}
+	var active []*Effect // nil if no effect is left
+	for _, e := range c.Effects {
+		e.Ticks -= ticks
+		if e.Ticks > 0 {
+			active = append(active, e)
+		}
+	}
+	c.Effects = active
+}
+
+// pickUp collects all items the cloud touches.
+func (c *Cloud) pickUp() {
+	w := c.world
+	if c.Player == "" || len(w.items) == 0 {
+		return // only player clouds collect items
+	}
+
+	ticks := int(math.Round(float64(w.rules.PowerUps.Duration) * float64(w.ticksPerSecond())))
+	rest := w.items[:0]
+	for _, i := range w.items {
+		x := i.Pos.X - c.Pos.X
+		y := i.Pos.Y - c.Pos.Y
+		if float32(math.Sqrt(float64(float32(x*x)+float32(y*y)))) < c.Radius()+ItemRadius {
+			c.addEffect(i.Kind, ticks) // picked up
+		} else {
+			rest = append(rest, i)
+		}
+	}
+	w.items = rest
+}
+
+// attract pulls neutral clouds within range towards the cloud (magnet).
+func (c *Cloud) attract(ticks int) {
+	for _, o := range c.world.clouds {
+		if o == c || o.Player != "" || o.IsDeath() {
+			continue
+		}
+		x := c.Pos.X - o.Pos.X
+		y := c.Pos.Y - o.Pos.Y
+		d := float32(math.Sqrt(float64(float32(x*x) + float32(y*y))))
+		if d > 0 && d < magnetRange {
+			o.Vel.add(NewVelocity(x/d, y/d), magnetForce*float32(ticks))
+		}
+	}
+}
+
+//----  World  -------------------------------------------------------------------------------------------------------//
+
+// spawnItems adds new items to the world (not thread-safe).
+// ticks is the duration of the update in game ticks (see SimSpeedUp).
+func (w *World) spawnItems(ticks int) {
+	rules := &w.rules.PowerUps
+	if rules.Interval <= 0 {
+		return // no power-ups
+	}
+
+	// interval in ticks (at least one)
+	interval := int(math.Round(float64(rules.Interval) * float64(w.ticksPerSecond())))
+	if interval < 1 {
+		interval = 1
+	}
+
+	w.itemTimer += ticks
+	if w.itemTimer < interval {
+		return // not yet
+	}
+
+	// possible kinds
+	kinds := rules.Kinds
+	if len(kinds) == 0 {
+		kinds = []string{ItemSpeed, ItemShield, ItemFree, ItemMagnet}
+	}
+
+	// same seed and iteration: same items (server and client simulations)
+	rnd := newRandom(rules.Seed, w.iteration)
+
+	n := 0 // items of this update (part of the UIDs)
+	for w.itemTimer >= interval {
+		w.itemTimer -= interval
+
+		// board is full
+		if rules.Max > 0 && len(w.items) >= rules.Max {
+			continue
+		}
+
+		// random item
+		// (the order of the random numbers is part of the protocol: kind, x, y)
+		kind := kinds[rnd.intn(len(kinds))]
+		x := ItemRadius + float32(rnd.float32()*(float32(w.width)-2*ItemRadius))
+		y := ItemRadius + float32(rnd.float32()*(float32(w.height)-2*ItemRadius))
+		if w.fixed() {
+			x, y = quantize(x), quantize(y)
+		}
+		item := &Item{
+			Kind: kind,
+			Pos:  NewPosition(x, y),
+			UID:  itemUID(rules.Seed, w.iteration, w.spawned, n),
+		}
+		n++
+		w.items = append(w.items, item)
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func initItemWorld(kind string) (*World, *Cloud) {
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w.SetRules(Rules{PowerUps: PowerUps{Duration: 1}})
+	w.items = []*Item{{Kind: kind, Pos: NewPosition(500, 500), UID: "item"}}
+	c := w.AddPlayer("Player 1", "red", NewPosition(480, 500), 100)
+	return w, c
+}
+
+func TestCloud_pickUp(t *testing.T) {
+	w, c := initItemWorld(ItemSpeed)
+	w.Update()
+
+	// picked up
+	if len(w.Items()) != 0 || !c.HasEffect(ItemSpeed) {
+		t.Fatalf("fail: %v %v", w.Items(), c.Effects)
+	}
+
+	// neutral clouds don't collect items
+	w.items = []*Item{{Kind: ItemShield, Pos: NewPosition(100, 100), UID: "item"}}
+	w.addCloud(NewCloud(w, NewPosition(100, 100), NewVelocity(0, 0), 100, "", ""))
+	w.Update()
+	if len(w.Items()) != 1 {
+		t.Errorf("fail: %v", w.Items())
+	}
+
+	// the effect ends after 1 sec (60 ticks)
+	for i := 0; i < 60; i++ {
+		w.Update()
+	}
+	if c.HasEffect(ItemSpeed) || c.Effects != nil {
+		t.Errorf("fail: %v", c.Effects)
+	}
+}
+
+func TestCloud_effects(t *testing.T) {
+	// speed: position += velocity * 0.1 * 2
+	w, c := initItemWorld(ItemSpeed)
+	w.Update()
+	c.Vel = NewVelocity(10, 0)
+	x := c.Pos.X
+	w.Update()
+	if !almostEqual(c.Pos.X, x+2) {
+		t.Errorf("speed fail: %v -> %v", x, c.Pos.X)
+	}
+
+	// free: move without vapor costs and exhaust cloud
+	w, c = initItemWorld(ItemFree)
+	w.Update()
+	var moves []Event
+	w.AddListener(func(e Event) {
+		if e.Kind == EventMove {
+			moves = append(moves, e)
+		}
+	})
+	if !w.Move(c, NewVelocity(10, 0)) || c.Vapor != 100 || len(w.Clouds()) != 1 || c.Vel.X <= 0 {
+		t.Errorf("free fail: %v %v %v", c.Vapor, c.Vel, len(w.Clouds()))
+	}
+	if len(moves) != 1 || moves[0].Amount != 0 || moves[0].X != 10 {
+		t.Errorf("free fail: %v", moves)
+	}
+
+	// shield: no vapor loss to bigger clouds
+	w, c = initItemWorld(ItemShield)
+	w.Update()
+	big := NewCloud(w, NewPosition(c.Pos.X+15, c.Pos.Y), NewVelocity(0, 0), 400, "", "")
+	w.addCloud(big)
+	w.Update()
+	if c.Vapor != 100 || big.Vapor != 400 {
+		t.Errorf("shield fail: %v %v", c.Vapor, big.Vapor)
+	}
+
+	// magnet: neutral clouds are attracted
+	w, c = initItemWorld(ItemMagnet)
+	w.Update()
+	neutral := NewCloud(w, NewPosition(c.Pos.X+200, c.Pos.Y), NewVelocity(0, 0), 10, "", "")
+	w.addCloud(neutral)
+	w.Update()
+	if neutral.Vel.X >= 0 || neutral.Vel.Y != 0 {
+		t.Errorf("magnet fail: %v", neutral.Vel)
+	}
+}
+
+func TestWorld_spawnItems(t *testing.T) {
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w.SetRules(Rules{PowerUps: PowerUps{Interval: 0.5, Duration: 5, Max: 3, Kinds: []string{ItemMagnet}}})
+
+	// 2 items per second, but max 3
+	for i := 0; i < 60*5; i++ {
+		w.Update()
+	}
+	items := w.Items()
+	if len(items) != 3 {
+		t.Fatalf("fail: %v", items)
+	}
+	for _, i := range items {
+		if i.Kind != ItemMagnet || i.Pos.X < ItemRadius || i.Pos.X > 1000-ItemRadius || i.Pos.Y < ItemRadius || i.Pos.Y > 1000-ItemRadius {
+			t.Errorf("fail: %v %v", i.Kind, i.Pos)
+		}
+	}
+
+	// same seed and iteration: same items (uids too)
+	w2 := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w2.SetRules(w.Rules())
+	for i := 0; i < 60*5; i++ {
+		w2.Update()
+	}
+	if !reflect.DeepEqual(items, w2.Items()) || items[0].UID == items[1].UID {
+		t.Errorf("fail:\n%v\n%v", items, w2.Items())
+	}
+
+	// items and effects are part of the json world
+	c := w.AddPlayer("Player 1", "red", NewPosition(100, 100), 100)
+	c.addEffect(ItemShield, 33)
+	clone := new(World)
+	clone.FromJson(w.ToJson())
+	if !reflect.DeepEqual(w.Items(), clone.Items()) || !reflect.DeepEqual(w.Clouds(), clone.Clouds()) {
+		t.Errorf("json fail:\n%v\n%v", w.Items(), clone.Items())
+	}
+}
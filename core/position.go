@@ -28,6 +28,6 @@ func (p *Position) add(v *Velocity, multi float32) {
 	if v == nil {
 		return
 	}
-	p.X += v.X * multi
-	p.Y += v.Y * multi
+	p.X += float32(v.X * multi) // explicit conversion: no fused multiply-add (see Precision)
+	p.Y += float32(v.Y * multi)
 }
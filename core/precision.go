@@ -0,0 +1,39 @@
+package core
+
+import (
+	"math"
+)
+
+// The simulation is deterministic and well-specified in both precision modes:
+//   - all values are float32 and every operation is rounded to float32 (no fused multiply-add)
+//   - square roots and trigonometric functions are calculated in float64 and rounded to float32
+//   - random numbers for rain and items come from a portable generator (SplitMix64)
+//
+// Client simulations in other languages often calculate with float64 (Python) or let the
+// compiler use higher precision, so they slowly diverge from the server. PrecisionFixed
+// rounds the state of all clouds to a fixed-point grid after each step, so that
+// small rounding errors of a client disappear instead of adding up.
+const (
+	PrecisionFloat32 = "float32" // plain float32 (DEFAULT)
+	PrecisionFixed   = "fixed"   // float32 rounded to multiples of 1/FixedScale
+	FixedScale       = 256       // 8 fractional bits: values up to 65536 are exact in float32
+)
+
+// fixed returns whether the world uses the fixed-point grid (not thread-safe).
+func (w *World) fixed() bool {
+	return w != nil && w.rules.Precision == PrecisionFixed
+}
+
+// quantize rounds a value to the nearest multiple of 1/FixedScale (halfway cases away from zero).
+func quantize(v float32) float32 {
+	return float32(math.Round(float64(v)*FixedScale) / FixedScale)
+}
+
+// quantize rounds position, velocity and vapor to the fixed-point grid.
+func (c *Cloud) quantize() {
+	c.Pos.X = quantize(c.Pos.X)
+	c.Pos.Y = quantize(c.Pos.Y)
+	c.Vel.X = quantize(c.Vel.X)
+	c.Vel.Y = quantize(c.Vel.Y)
+	c.Vapor = quantize(c.Vapor)
+}
@@ -0,0 +1,33 @@
+package core
+
+// random is a small portable pseudo random number generator (SplitMix64).
+// It is used for rainclouds and items, so that client simulations in other languages
+// produce exactly the same values as the server (math/rand is specific to Go).
+type random struct {
+	state uint64
+}
+
+// newRandom creates a generator for the given seed and world iteration.
+// state = seed XOR (iteration * 0x9E3779B97F4A7C15)
+func newRandom(seed int64, iteration uint64) *random {
+	return &random{state: uint64(seed) ^ iteration*0x9E3779B97F4A7C15}
+}
+
+// next returns the next 64-bit random number.
+func (r *random) next() uint64 {
+	r.state += 0x9E3779B97F4A7C15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// float32 returns a number in [0,1) from the upper 24 bits (exact in float32).
+func (r *random) float32() float32 {
+	return float32(r.next()>>40) / (1 << 24)
+}
+
+// intn returns a number in [0,n).
+func (r *random) intn(n int) int {
+	return int(r.next() % uint64(n))
+}
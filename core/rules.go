@@ -0,0 +1,59 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Rules contains optional game rules.
+// The zero value is the classic game.
+type Rules struct {
+	Wind     []*WindField // global wind fields (DEFAULT: none)
+	Weather  Weather      // evaporation and rain (DEFAULT: off)
+	PowerUps PowerUps     // collectible items (DEFAULT: off)
+	Gravity  Gravity      // attraction between clouds (DEFAULT: off)
+
+	Precision string // PrecisionFloat32 or PrecisionFixed (DEFAULT: float32)
+}
+
+// LoadRules reads the rules from a json file.
+// Wind maps (grid wind fields with a File) are loaded relative to the rules file.
+func LoadRules(path string) (Rules, error) {
+	var r Rules
+
+	// read file
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return r, fmt.Errorf("%s: %v", path, err)
+	}
+
+	// precision
+	if r.Precision != "" && r.Precision != PrecisionFloat32 && r.Precision != PrecisionFixed {
+		return r, fmt.Errorf("%s: unknown precision '%s'", path, r.Precision)
+	}
+
+	// load wind maps
+	for i, f := range r.Wind {
+		if f == nil {
+			return r, fmt.Errorf("%s: wind %d is empty", path, i)
+		}
+		if f.Kind == WindGrid && f.File != "" && len(f.Grid) == 0 {
+			file := f.File
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			m, err := LoadWindMap(file, f.CellSize)
+			if err != nil {
+				return r, err
+			}
+			r.Wind[i] = m
+		}
+	}
+
+	return r, nil
+}
@@ -29,7 +29,7 @@ func NewVelocityByAngle(angle, strength float32) *Velocity {
 
 // Strength is the hypotenuse of x and y and stands for the speed in one direction.
 func (v *Velocity) Strength() float32 {
-	return float32(math.Sqrt(float64(v.X*v.X + v.Y*v.Y)))
+	return float32(math.Sqrt(float64(float32(v.X*v.X) + float32(v.Y*v.Y))))
 }
 
 // clone creates a new instance of Velocity and initializes all its fields with exactly the contents.
@@ -44,8 +44,8 @@ func (v *Velocity) add(o *Velocity, multi float32) {
 	if o == nil {
 		return
 	}
-	v.X += o.X * multi
-	v.Y += o.Y * multi
+	v.X += float32(o.X * multi) // explicit conversion: no fused multiply-add (see Precision)
+	v.Y += float32(o.Y * multi)
 }
 
 // multi the values x and y by the factor m
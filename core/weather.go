@@ -0,0 +1,96 @@
+package core
+
+import (
+	"math"
+)
+
+// Weather contains the optional rules for evaporation and rain.
+// If these rules are active, the total amount of vapor in the world is no longer constant.
+// The zero value disables both.
+type Weather struct {
+	EvaporationRate float32 // vapor per second that small neutral clouds lose (0 = off)
+	EvaporationSize float32 // only neutral clouds with less vapor evaporate
+	RainInterval    float32 // seconds between two new rainclouds (0 = off)
+	RainMinVapor    float32 // min vapor of new rainclouds
+	RainMaxVapor    float32 // max vapor of new rainclouds
+	RainMaxSpeed    float32 // max initial speed of new rainclouds
+	RainArea        Area    // rainclouds spawn in this region (zero value: whole game board)
+	Seed            int64   // random seed for rainclouds
+}
+
+// Area is a rectangular region of the game board.
+type Area struct {
+	X      float32
+	Y      float32
+	Width  float32
+	Height float32
+}
+
+// evaporate reduces the vapor of small neutral clouds.
+// seconds is the duration of the update.
+func (c *Cloud) evaporate(weather *Weather, seconds float32) {
+	if weather.EvaporationRate <= 0 || c.Player != "" || c.Vapor >= weather.EvaporationSize {
+		return // no evaporation
+	}
+
+	loss := float32(weather.EvaporationRate * seconds)
+	if loss > c.Vapor {
+		loss = c.Vapor
+	}
+	c.Vapor -= loss
+	c.world.evaporated += loss
+}
+
+// rain spawns new rainclouds (not thread-safe).
+// ticks is the duration of the update in game ticks (see SimSpeedUp).
+func (w *World) rain(ticks int) {
+	weather := &w.rules.Weather
+	if weather.RainInterval <= 0 {
+		return // no rain
+	}
+
+	// interval in ticks (at least one)
+	interval := int(math.Round(float64(weather.RainInterval) * float64(w.ticksPerSecond())))
+	if interval < 1 {
+		interval = 1
+	}
+
+	w.rainTimer += ticks
+	if w.rainTimer < interval {
+		return // not yet
+	}
+
+	// rain area
+	area := weather.RainArea
+	if area.Width <= 0 || area.Height <= 0 {
+		area = Area{Width: float32(w.width), Height: float32(w.height)}
+	}
+
+	// same seed and iteration: same rainclouds (server and client simulations)
+	rnd := newRandom(weather.Seed, w.iteration)
+
+	for w.rainTimer >= interval {
+		w.rainTimer -= interval
+
+		// random raincloud
+		// (the order of the random numbers is part of the protocol: x, y, vx, vy, vapor)
+		x := area.X + float32(rnd.float32()*area.Width)
+		y := area.Y + float32(rnd.float32()*area.Height)
+		vx := float32((2*rnd.float32() - 1) * weather.RainMaxSpeed)
+		vy := float32((2*rnd.float32() - 1) * weather.RainMaxSpeed)
+		vap := weather.RainMinVapor + float32(rnd.float32()*(weather.RainMaxVapor-weather.RainMinVapor))
+		if w.fixed() {
+			x, y, vx, vy, vap = quantize(x), quantize(y), quantize(vx), quantize(vy), quantize(vap)
+		}
+		pos := NewPosition(x, y)
+		vel := NewVelocity(vx, vy)
+		if vap < 1 {
+			continue // a dead cloud
+		}
+
+		// add to world
+		w.addCloud(NewCloud(w, pos, vel, vap, "", ""))
+		w.worldVapor += vap
+		w.rained += vap
+	}
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestCloud_evaporate(t *testing.T) {
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w.SetRules(Rules{Weather: Weather{EvaporationRate: 6, EvaporationSize: 50}})
+	small := NewCloud(w, NewPosition(100, 100), NewVelocity(0, 0), 10, "", "")
+	big := NewCloud(w, NewPosition(500, 500), NewVelocity(0, 0), 100, "", "")
+	player := NewCloud(w, NewPosition(800, 800), NewVelocity(0, 0), 10, "Player 1", "red")
+	w.addCloud(small)
+	w.addCloud(big)
+	w.addCloud(player)
+	w.Update()
+
+	// 6 vapor per second = 0.1 per update
+	if !almostEqual(small.Vapor, 9.9) {
+		t.Errorf("fail: %v", small.Vapor)
+	}
+	if big.Vapor != 100 || player.Vapor != 10 {
+		t.Errorf("fail: %v %v", big.Vapor, player.Vapor)
+	}
+	if evaporated, _ := w.VaporBalance(); !almostEqual(evaporated, 0.1) {
+		t.Errorf("fail: %v", evaporated)
+	}
+}
+
+func TestWorld_rain(t *testing.T) {
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w.SetRules(Rules{Weather: Weather{RainInterval: 1, RainMinVapor: 20, RainMaxVapor: 30, RainArea: Area{X: 100, Y: 200, Width: 10, Height: 10}, Seed: 42}})
+
+	// one raincloud per second
+	for i := 0; i < 60; i++ {
+		w.Update()
+	}
+	clouds := w.Clouds()
+	if len(clouds) != 1 {
+		t.Fatalf("fail: %d clouds", len(clouds))
+	}
+	c := clouds[0]
+	if c.Vapor < 20 || c.Vapor > 30 || c.Pos.X < 100 || c.Pos.X > 110 || c.Pos.Y < 200 || c.Pos.Y > 210 {
+		t.Errorf("fail: %v %v", c.Vapor, c.Pos)
+	}
+	_, worldVapor, _, _, _ := w.Stats()
+	if _, rained := w.VaporBalance(); rained != c.Vapor || worldVapor != c.Vapor {
+		t.Errorf("fail: %v %v", worldVapor, rained)
+	}
+
+	// the rain is the same in a cloned world
+	clone := w.Clone()
+	for i := 0; i < 60; i++ {
+		w.Update()
+		clone.Update()
+	}
+	c1, c2 := w.Clouds(), clone.Clouds()
+	if len(c1) != 2 || len(c2) != 2 || *c1[1].Pos != *c2[1].Pos || c1[1].Vapor != c2[1].Vapor {
+		t.Errorf("fail:\n%v\n%v", c1, c2)
+	}
+}
+
+func TestWorld_vaporAccounting(t *testing.T) {
+	w := NewWorld(2000, 1000, 60, 100, 20, 300, 1337)
+	w.SetRules(Rules{Weather: Weather{EvaporationRate: 5, EvaporationSize: 40, RainInterval: 0.5, RainMinVapor: 10, RainMaxVapor: 80, RainMaxSpeed: 5, Seed: 7}})
+	p1 := w.AddPlayer("Player 1", "red", NewPosition(300, 300), 1000)
+	w.AddPlayer("Player 2", "blue", NewPosition(1300, 700), 1000)
+	w.Update()
+	startEvaporated, startRained := w.VaporBalance()
+	start := cloudVapor(w) + startEvaporated - startRained
+
+	for i := 0; i < 3000; i++ {
+		if i%100 == 0 {
+			w.Move(p1, NewVelocityByAngle(float32(i), 20))
+		}
+		if i == 2000 {
+			w.Kill(p1)
+		}
+		w.Update()
+	}
+
+	// initial vapor = vapor of all clouds + evaporated - rained
+	evaporated, rained := w.VaporBalance()
+	worldVapor := cloudVapor(w)
+	if now := worldVapor + evaporated - rained; now/start < 0.999 || now/start > 1.001 {
+		t.Errorf("vapor accounting fail: start=%v now=%v (world=%v evaporated=%v rained=%v)", start, now, worldVapor, evaporated, rained)
+	}
+	if evaporated == 0 || rained == 0 {
+		t.Errorf("fail: evaporated=%v rained=%v", evaporated, rained)
+	}
+}
+
+// cloudVapor is the vapor of all clouds (worldVapor is summed during the update, see World.Stats)
+func cloudVapor(w *World) float32 {
+	var sum float32
+	for _, c := range w.Clouds() {
+		sum += c.Vapor
+	}
+	return sum
+}
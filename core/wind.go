@@ -0,0 +1,167 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// wind field kinds
+const (
+	WindDrift  = "drift"  // constant drift in direction X;Y
+	WindVortex = "vortex" // rotation around the center X;Y
+	WindGust   = "gust"   // drift in direction X;Y that swells and dies down periodically
+	WindGrid   = "grid"   // vector field loaded from a wind map
+)
+
+// WindField is a global wind that is applied to every cloud in the world.
+// The velocity of each cloud is changed by the wind with every update.
+//   velocity += wind * 0.01
+// A wind field must not be changed after it is added to the rules (it is shared between cloned worlds).
+type WindField struct {
+	Kind     string       // drift, vortex, gust or grid
+	X        float32      // drift & gust: wind direction  /  vortex: center
+	Y        float32      // drift & gust: wind direction  /  vortex: center
+	Strength float32      // vortex: wind speed at the edge of the eye (negative: counterclockwise)
+	Radius   float32      // vortex: radius of the eye
+	Period   float32      // gust: duration of a gust in seconds
+	CellSize float32      // grid: width and height of a map cell
+	Grid     [][]Velocity // grid: rows of wind vectors
+	File     string       // grid: wind map file (see LoadWindMap)
+}
+
+// NewDriftWind create a constant wind in one direction.
+func NewDriftWind(x, y float32) *WindField {
+	return &WindField{Kind: WindDrift, X: x, Y: y}
+}
+
+// NewVortexWind create a clockwise rotating wind around a center (rankine vortex).
+// Inside the eye (radius) the wind speed increases linearly, outside it decreases with the distance.
+func NewVortexWind(x, y, strength, radius float32) *WindField {
+	return &WindField{Kind: WindVortex, X: x, Y: y, Strength: strength, Radius: radius}
+}
+
+// NewGustWind create a wind in one direction that swells from zero to full strength and dies down again.
+// period is the duration of one gust in seconds.
+func NewGustWind(x, y, period float32) *WindField {
+	return &WindField{Kind: WindGust, X: x, Y: y, Period: period}
+}
+
+// LoadWindMap loads a grid wind field from a text file (see ParseWindMap).
+func LoadWindMap(path string, cellSize float32) (*WindField, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ParseWindMap(string(b), cellSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	f.File = path
+	return f, nil
+}
+
+// ParseWindMap creates a grid wind field from a text map.
+// Each line is one row of cells from top to bottom. The cells are separated by spaces
+// and each cell is a wind vector in the form 'x;y' (like the move command).
+// Empty lines and lines starting with '#' are ignored.
+//   # two rows with three cells
+//   1;0  1;0   0;1
+//   0;-1 -1;0 -1;0
+func ParseWindMap(str string, cellSize float32) (*WindField, error) {
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("invalid cell size: %v", cellSize)
+	}
+
+	grid := make([][]Velocity, 0)
+	scanner := bufio.NewScanner(strings.NewReader(str))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue // ignore
+		}
+
+		row := make([]Velocity, 0)
+		for _, cell := range strings.Fields(text) {
+			a := strings.Split(cell, ";")
+			if len(a) != 2 {
+				return nil, fmt.Errorf("line %d: invalid cell '%s': use 'float32;float32'", line, cell)
+			}
+			x, errX := strconv.ParseFloat(a[0], 32)
+			y, errY := strconv.ParseFloat(a[1], 32)
+			if errX != nil || errY != nil {
+				return nil, fmt.Errorf("line %d: invalid cell '%s': use 'float32;float32'", line, cell)
+			}
+			row = append(row, Velocity{X: float32(x), Y: float32(y)})
+		}
+		grid = append(grid, row)
+	}
+
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("empty wind map")
+	}
+	return &WindField{Kind: WindGrid, CellSize: cellSize, Grid: grid}, nil
+}
+
+//----  GETTER  ------------------------------------------------------------------------------------------------------//
+
+// At returns the wind at a position of the game board.
+// seconds is the game time (iteration / game speed) and is needed for time-varying winds.
+func (f *WindField) At(x, y, seconds float32) (wx, wy float32) {
+	switch f.Kind {
+	case WindDrift:
+		return f.X, f.Y
+
+	case WindGust:
+		if f.Period <= 0 {
+			return f.X, f.Y
+		}
+		// swell from 0 to 1 and back to 0 in one period
+		s := float32(1-math.Cos(2*math.Pi*float64(seconds/f.Period))) / 2
+		return f.X * s, f.Y * s
+
+	case WindVortex:
+		dx := x - f.X
+		dy := y - f.Y
+		r := float32(math.Sqrt(float64(float32(dx*dx) + float32(dy*dy))))
+		if r == 0 || f.Radius <= 0 {
+			return 0, 0
+		}
+		// wind speed: linear inside the eye, 1/r outside
+		speed := f.Strength * f.Radius / r
+		if r < f.Radius {
+			speed = f.Strength * r / f.Radius
+		}
+		// tangential direction
+		return -dy / r * speed, dx / r * speed
+
+	case WindGrid:
+		if f.CellSize <= 0 || len(f.Grid) == 0 {
+			return 0, 0
+		}
+		row := clampIndex(int(y/f.CellSize), len(f.Grid))
+		if len(f.Grid[row]) == 0 {
+			return 0, 0
+		}
+		col := clampIndex(int(x/f.CellSize), len(f.Grid[row]))
+		v := f.Grid[row][col]
+		return v.X, v.Y
+
+	default:
+		return 0, 0
+	}
+}
+
+// clampIndex keeps i in the range [0, length-1]
+func clampIndex(i, length int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= length {
+		return length - 1
+	}
+	return i
+}
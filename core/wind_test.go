@@ -0,0 +1,114 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWindField_At(t *testing.T) {
+	// drift is constant
+	if x, y := NewDriftWind(3, -2).At(100, 200, 5); x != 3 || y != -2 {
+		t.Errorf("drift fail: %v %v", x, y)
+	}
+
+	// gust: calm at the beginning, full strength in the middle of the period
+	gust := NewGustWind(4, 0, 10)
+	if x, y := gust.At(0, 0, 0); !almostEqual(x, 0) || !almostEqual(y, 0) {
+		t.Errorf("gust fail: %v %v", x, y)
+	}
+	if x, y := gust.At(0, 0, 5); !almostEqual(x, 4) || !almostEqual(y, 0) {
+		t.Errorf("gust fail: %v %v", x, y)
+	}
+
+	// vortex: clockwise rotation on the screen, max speed at the edge of the eye
+	vortex := NewVortexWind(500, 500, 2, 100)
+	if x, y := vortex.At(600, 500, 0); !almostEqual(x, 0) || !almostEqual(y, 2) {
+		t.Errorf("vortex fail: %v %v", x, y)
+	}
+	if x, y := vortex.At(550, 500, 0); !almostEqual(x, 0) || !almostEqual(y, 1) {
+		t.Errorf("vortex fail: %v %v", x, y)
+	}
+	if x, y := vortex.At(500, 700, 0); !almostEqual(x, -1) || !almostEqual(y, 0) {
+		t.Errorf("vortex fail: %v %v", x, y)
+	}
+	if x, y := vortex.At(500, 500, 0); x != 0 || y != 0 {
+		t.Errorf("vortex center fail: %v %v", x, y)
+	}
+
+	// grid: cells and clamping at the edges
+	grid, err := ParseWindMap("# comment\n1;0 2;0\n\n0;1 0;2\n", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, y := grid.At(50, 50, 0); x != 1 || y != 0 {
+		t.Errorf("grid fail: %v %v", x, y)
+	}
+	if x, y := grid.At(150, 150, 0); x != 0 || y != 2 {
+		t.Errorf("grid fail: %v %v", x, y)
+	}
+	if x, y := grid.At(-50, 9999, 0); x != 0 || y != 1 {
+		t.Errorf("grid clamp fail: %v %v", x, y)
+	}
+}
+
+func TestParseWindMap(t *testing.T) {
+	if _, err := ParseWindMap("1;0 2", 100); err == nil {
+		t.Error("invalid cell accepted")
+	}
+	if _, err := ParseWindMap("1;x", 100); err == nil {
+		t.Error("invalid float accepted")
+	}
+	if _, err := ParseWindMap("# nothing", 100); err == nil {
+		t.Error("empty map accepted")
+	}
+	if _, err := ParseWindMap("1;0", 0); err == nil {
+		t.Error("invalid cell size accepted")
+	}
+}
+
+func TestWorld_Wind(t *testing.T) {
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	w.SetRules(Rules{Wind: []*WindField{NewDriftWind(10, 0)}})
+	c := w.AddPlayer("Player 1", "red", NewPosition(500, 500), 100)
+	w.Update()
+
+	// velocity += wind * 0.01 (and damping)
+	if !almostEqual(c.Vel.X, 0.1*0.999) || c.Vel.Y != 0 {
+		t.Errorf("fail: %v", c.Vel)
+	}
+	if v := w.WindAt(0, 0); v.X != 10 || v.Y != 0 {
+		t.Errorf("fail: %v", v)
+	}
+
+	// the rules are part of the json world
+	clone := new(World)
+	clone.FromJson(w.ToJson())
+	if !reflect.DeepEqual(w.Rules(), clone.Rules()) {
+		t.Errorf("rules not equal:\n%v\n%v", w.Rules(), clone.Rules())
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "rules.json")
+	mapFile := filepath.Join(dir, "wind.txt")
+	_ = os.WriteFile(mapFile, []byte("1;0 0;1"), 0644)
+	_ = os.WriteFile(rulesFile, []byte(`{"Wind":[{"Kind":"drift","X":1},{"Kind":"grid","CellSize":50,"File":"wind.txt"}]}`), 0644)
+
+	r, err := LoadRules(rulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Wind) != 2 || r.Wind[0].X != 1 {
+		t.Errorf("fail: %v", r.Wind)
+	}
+	if x, y := r.Wind[1].At(60, 0, 0); x != 0 || y != 1 {
+		t.Errorf("wind map fail: %v %v", x, y)
+	}
+
+	if _, err := LoadRules(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing file accepted")
+	}
+}
@@ -1,8 +1,6 @@
 package core
 
 import (
-	secRnd "crypto/rand"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -24,13 +22,35 @@ type World struct {
 	alive        int
 	winCondition bool
 	leader       string
+	evaporated   float32 // vapor lost by evaporation and dead clouds
+	rained       float32 // vapor added by rain
 
 	// cloud list
 	clouds []*Cloud
 	mux    *sync.Mutex
 
-	SimSpeedUp int  // dirty hack for faster simulations (DEFAULT: 1)
-	freeze     bool // block updates (DEFAULT: false)
+	// optional game rules
+	rules     Rules
+	rainTimer int          // game ticks since the last rain
+	spawned   uint64       // clouds added to the world (part of the UIDs)
+	items     []*Item      // power-ups on the game board
+	itemTimer int          // game ticks since the last item
+	grid      *spatialGrid // spatial index of the gravity (reused between updates)
+
+	// snapshots of the last iterations (see EnableHistory)
+	history  []*World
+	commands []*command // move and kill commands of the history (see MoveAt)
+
+	// events (see AddListener)
+	listeners []*listener
+	events    []Event
+	past      []Event // emitted events of the history window (see MoveAt)
+	replay    []Event // events of a resimulation (see MoveAt)
+	replaying bool
+
+	SimSpeedUp  int  // dirty hack for faster simulations (DEFAULT: 1)
+	SubStepping bool // Update() runs SimSpeedUp regular game ticks instead of one large step (DEFAULT: false)
+	freeze      bool // block updates (DEFAULT: false)
 }
 
 // NewWorld create a new World.
@@ -102,6 +122,53 @@ func (w *World) Stats() (iteration uint64, worldVapor float32, alive int, winCon
 	return
 }
 
+// VaporBalance returns the vapor that has left or entered the game (see Weather).
+// evaporated is the vapor that has left the game (evaporation and the remains of dead clouds).
+// rained is the vapor that was added by rainclouds.
+// The vapor at the start of the game is always the vapor of all clouds + evaporated - rained.
+func (w *World) VaporBalance() (evaporated, rained float32) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.evaporated, w.rained
+}
+
+// Rules returns the optional game rules of this world.
+func (w *World) Rules() Rules {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.rules
+}
+
+// WindAt returns the sum of all wind fields at a position of the game board.
+func (w *World) WindAt(x, y float32) *Velocity {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	ret := NewVelocity(0, 0)
+	seconds := w.seconds()
+	for _, f := range w.rules.Wind {
+		wx, wy := f.At(x, y, seconds)
+		ret.X += wx
+		ret.Y += wy
+	}
+	return ret
+}
+
+// Items returns a list of power-ups on the game board.
+// This function creates a new instance of the list and of all items.
+func (w *World) Items() []*Item {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	var ret = make([]*Item, 0, len(w.items))
+	for _, i := range w.items {
+		ret = append(ret, i.clone())
+	}
+	return ret
+}
+
 // Clouds returns a list of clouds.
 // This function is equal to clone() and creates a new instance
 // of the list and initializes all its fields with exactly the contents.
@@ -131,11 +198,30 @@ func (w *World) Me(name string) *Cloud {
 	return nil // player cloud not found
 }
 
+// Alive checks if the cloud of a player exists and is not dead (thread-safe, unlike Me(name).IsDeath()).
+func (w *World) Alive(name string) bool {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	for _, c := range w.clouds {
+		if c.Player == name {
+			return !c.IsDeath()
+		}
+	}
+	return false
+}
+
 // Clone creates a new instance of World and initializes all its fields with exactly the contents.
+// The history (see EnableHistory) is not copied.
 func (w *World) Clone() *World {
 	w.mux.Lock()
 	defer w.mux.Unlock()
 
+	return w.clone()
+}
+
+// clone is the helper of Clone (not thread-safe).
+func (w *World) clone() *World {
 	// new world
 	ret := &World{
 		width:     w.width,
@@ -147,18 +233,29 @@ func (w *World) Clone() *World {
 		alive:        w.alive,
 		winCondition: w.winCondition,
 		leader:       w.leader,
+		evaporated:   w.evaporated,
+		rained:       w.rained,
 
 		clouds: make([]*Cloud, 0, len(w.clouds)),
 		mux:    new(sync.Mutex),
 
-		SimSpeedUp: w.SimSpeedUp,
-		freeze:     w.freeze,
+		rules:     w.rules,
+		rainTimer: w.rainTimer,
+		spawned:   w.spawned,
+		itemTimer: w.itemTimer,
+
+		SimSpeedUp:  w.SimSpeedUp,
+		SubStepping: w.SubStepping,
+		freeze:      w.freeze,
 	}
 
-	// set clouds
+	// set clouds and items
 	for _, c := range w.clouds {
 		ret.clouds = append(ret.clouds, c.clone())
 	}
+	for _, i := range w.items {
+		ret.items = append(ret.items, i.clone())
+	}
 
 	// rep. world links
 	for _, c := range ret.clouds {
@@ -174,6 +271,7 @@ func (w *World) Clone() *World {
 // AddPlayer add a new player cloud to the world.
 // If pos is nil, then a random position is chosen.
 func (w *World) AddPlayer(name, color string, pos *Position, vapor float32) *Cloud {
+	defer w.dispatch()
 	w.mux.Lock()
 	defer w.mux.Unlock()
 
@@ -207,6 +305,14 @@ func (w *World) AddPlayer(name, color string, pos *Position, vapor float32) *Clo
 	return c
 }
 
+// SetRules changes the optional game rules of this world.
+func (w *World) SetRules(r Rules) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	w.rules = r
+}
+
 // Freeze can freeze the world. Then there are no updates and all movement commands are discarded.
 func (w *World) Freeze(b bool) {
 	w.mux.Lock()
@@ -217,23 +323,33 @@ func (w *World) Freeze(b bool) {
 
 // Move executes the move command of the cloud in the world.
 func (w *World) Move(c *Cloud, wind *Velocity) bool {
+	defer w.dispatch()
 	w.mux.Lock()
 	defer w.mux.Unlock()
 
 	if c == nil || c.world == nil || c.world.freeze {
 		return false
+	} else if c.move(wind) {
+		w.logCommand(w.iteration, c.UID, wind.clone())
+		return true
 	} else {
-		return c.move(wind)
+		w.emit(Event{Kind: EventReject, UID: c.UID, Player: c.Player, X: wind.X, Y: wind.Y})
+		return false
 	}
 }
 
 // Kill is a suicide order. The cloud explodes.
 func (w *World) Kill(c *Cloud) bool {
+	defer w.dispatch()
 	w.mux.Lock()
 	defer w.mux.Unlock()
 
-	if c != nil && c.world != nil {
-		return c.kill()
+	if c != nil && c.world != nil && c.kill() {
+		w.logCommand(w.iteration, c.UID, nil)
+		if c.Player != "" {
+			w.emit(Event{Kind: EventDeath, UID: c.UID, Player: c.Player, Other: c.UID, OtherPlayer: c.Player})
+		}
+		return true
 	} else {
 		return false
 	}
@@ -241,7 +357,13 @@ func (w *World) Kill(c *Cloud) bool {
 
 // Update calls Cloud.update() for each cloud in the world.
 // The world statistics are also calculated.
+//
+// With SimSpeedUp, the clouds normally move a multiple of the distance in one step. Small and fast clouds
+// can jump over other clouds and the result differs from the same number of regular updates.
+// With SubStepping, Update runs SimSpeedUp regular game ticks (and the iteration increases by SimSpeedUp),
+// so the result is exactly the same as SimSpeedUp calls of Update without speed up.
 func (w *World) Update() {
+	defer w.dispatch()
 	w.mux.Lock()
 	defer w.mux.Unlock()
 
@@ -250,11 +372,34 @@ func (w *World) Update() {
 		return
 	}
 
+	// sub-stepping
+	steps := 1
+	if w.SubStepping && w.SimSpeedUp > 1 {
+		steps = w.SimSpeedUp
+	}
+	for i := 0; i < steps; i++ {
+		w.update()
+	}
+}
+
+// update is one step of Update() (not thread-safe)
+func (w *World) update() {
+	// snapshot before the changes of this iteration
+	w.record()
+
+	// attraction between clouds (before movement)
+	w.gravity(w.updateTicks())
+
 	// update AND remove dead Clouds
 	var worldVapor float32
 	var alive int
 	var newList = make([]*Cloud, 0, len(w.clouds))
 	for _, c := range w.clouds {
+		// the remains of dead clouds dissolve
+		if c.IsDeath() {
+			w.evaporated += c.Vapor
+		}
+
 		// changes
 		c.update()
 
@@ -262,6 +407,8 @@ func (w *World) Update() {
 		if !c.IsDeath() || c.Player != "" {
 			newList = append(newList, c)
 			worldVapor += c.Vapor
+		} else {
+			w.evaporated += c.Vapor // removed
 		}
 
 		// stats
@@ -271,25 +418,63 @@ func (w *World) Update() {
 		}
 	}
 
+	// fixed-point grid (optional rule)
+	if w.fixed() {
+		for _, c := range newList {
+			c.quantize()
+		}
+	}
+
 	// set new attributes
 	w.clouds = newList
 	w.iteration++
 	w.worldVapor = worldVapor
 	w.alive = alive
+	w.rain(w.updateTicks())
+	w.spawnItems(w.updateTicks())
+	won := w.winCondition
 	w.winCondition, w.leader = w.isWinner()
+	if w.winCondition && !won {
+		w.emit(Event{Kind: EventWinner, Player: w.leader})
+	}
+	w.emit(Event{Kind: EventTick})
 }
 
 // addCloud is a helper (not thread-safe)
 func (w *World) addCloud(c *Cloud) {
-	// generate uid
-	uid := make([]byte, 6)
-	_, _ = secRnd.Read(uid)
-
 	// set uid
-	c.UID = base64.StdEncoding.EncodeToString(uid)
+	c.UID = cloudUID(w.iteration, w.spawned, c)
+	w.spawned++
 
 	// add to list
 	w.clouds = append(w.clouds, c)
+	w.emit(Event{Kind: EventSpawn, UID: c.UID, Player: c.Player, Amount: c.Vapor, X: c.Pos.X, Y: c.Pos.Y})
+}
+
+// seconds returns the game time in seconds (not thread-safe)
+func (w *World) seconds() float32 {
+	return float32(w.iteration) / float32(w.ticksPerSecond())
+}
+
+// updateTicks returns the number of game ticks of one update step (see SimSpeedUp and SubStepping)
+func (w *World) updateTicks() int {
+	if w.SimSpeedUp != 0 && !w.SubStepping {
+		return w.SimSpeedUp
+	}
+	return 1
+}
+
+// updateSeconds returns the game time in seconds of one update (not thread-safe)
+func (w *World) updateSeconds() float32 {
+	return float32(w.updateTicks()) / float32(w.ticksPerSecond())
+}
+
+// ticksPerSecond is the game speed (DEFAULT: 60)
+func (w *World) ticksPerSecond() int {
+	if w.gameSpeed > 0 {
+		return w.gameSpeed
+	}
+	return 60
 }
 
 // isWinner returns whether the victory conditions have been met and who is currently in the lead.
@@ -340,8 +525,17 @@ type jsonWorld struct {
 	Alive        int
 	WinCondition bool
 	Leader       string
+	Evaporated   float32
+	Rained       float32
 	Clouds       []*Cloud
 	SimSpeedUp   int
+	SubStepping  bool
+	Rules        Rules
+	RainTimer    int
+	Spawned      uint64
+	Items        []*Item
+	ItemTimer    int
+	Checksum     string `json:",omitempty"` // see Checksum (output only)
 }
 
 // ToJson return the world as json string.
@@ -359,8 +553,17 @@ func (w *World) ToJson() string {
 		Alive:        w.alive,
 		WinCondition: w.winCondition,
 		Leader:       w.leader,
+		Evaporated:   w.evaporated,
+		Rained:       w.rained,
 		Clouds:       w.clouds,
 		SimSpeedUp:   w.SimSpeedUp,
+		SubStepping:  w.SubStepping,
+		Rules:        w.rules,
+		RainTimer:    w.rainTimer,
+		Spawned:      w.spawned,
+		Items:        w.items,
+		ItemTimer:    w.itemTimer,
+		Checksum:     fmt.Sprintf("%016x", w.checksum()),
 	}
 
 	// serialisation
@@ -399,8 +602,22 @@ func (w *World) FromJson(str string) {
 	w.alive = jw.Alive
 	w.winCondition = jw.WinCondition
 	w.leader = jw.Leader
+	w.evaporated = jw.Evaporated
+	w.rained = jw.Rained
 	w.clouds = jw.Clouds
 	w.SimSpeedUp = jw.SimSpeedUp
+	w.SubStepping = jw.SubStepping
+	w.rules = jw.Rules
+	w.rainTimer = jw.RainTimer
+	w.spawned = jw.Spawned
+	w.items = jw.Items
+	w.itemTimer = jw.ItemTimer
+
+	// old snapshots are invalid
+	if w.history != nil {
+		w.history = make([]*World, len(w.history))
+		w.commands = nil
+	}
 
 	// repair world links
 	for _, c := range w.clouds {
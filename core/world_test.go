@@ -65,6 +65,7 @@ func TestNewWorld(t *testing.T) {
 		mux:          nil, // fix this
 		SimSpeedUp:   1,
 		gameSpeed:    60,
+		spawned:      5, // neutral clouds
 	}
 
 	// fix clout und mux
@@ -111,6 +112,18 @@ func TestWorld_Me(t *testing.T) {
 	//       no clone, direct ref!
 }
 
+func TestWorld_Alive(t *testing.T) {
+	w := NewWorld(1000, 1000, 60, 0, 0, 0, 1337)
+	me := w.AddPlayer("Player 1", "red", &Position{X: 500, Y: 500}, 400)
+	if !w.Alive("Player 1") || w.Alive("Player 2") {
+		t.Errorf("fail: %v %v", w.Alive("Player 1"), w.Alive("Player 2"))
+	}
+	w.Kill(me)
+	if w.Alive("Player 1") {
+		t.Errorf("fail: dead player alive")
+	}
+}
+
 func TestWorld_AddPlayer(t *testing.T) {
 	// TODO: implement
 }
@@ -126,3 +139,55 @@ func TestWorld_Kill(t *testing.T) {
 func TestWorld_Update(t *testing.T) {
 	// TODO: implement
 }
+
+func TestWorld_SubStepping(t *testing.T) {
+	origin := initTestWorld()
+	origin.Move(origin.Me("Player 1"), NewVelocityByAngle(200, 300))
+	origin.Move(origin.Me("Player 2"), NewVelocityByAngle(20, 150))
+
+	// SimSpeedUp=10 with sub-stepping
+	fast := origin.Clone()
+	fast.SimSpeedUp = 10
+	fast.SubStepping = true
+	for i := 0; i < 30; i++ {
+		fast.Update()
+	}
+
+	// 10 normal updates
+	normal := origin.Clone()
+	for i := 0; i < 30*10; i++ {
+		normal.Update()
+	}
+
+	// equal test
+	fast.SimSpeedUp = 1
+	fast.SubStepping = false
+	if !reflect.DeepEqual(fast, normal) {
+		t.Error("fast and normal not equal")
+	}
+}
+
+func TestWorld_SubSteppingTunneling(t *testing.T) {
+	// a small and fast cloud flies towards a big cloud
+	w := NewWorld(2000, 1000, 60, 0, 0, 0, 0)
+	small := NewCloud(w, NewPosition(100, 500), NewVelocity(200, 0), 4, "", "")
+	big := NewCloud(w, NewPosition(250, 500), NewVelocity(0, 0), 100, "", "")
+	w.addCloud(small)
+	w.addCloud(big)
+
+	// one large step jumps over the big cloud (200 units)
+	jump := w.Clone()
+	jump.SimSpeedUp = 10
+	jump.Update()
+	if c := jump.Clouds(); len(c) != 2 || c[0].Pos.X < 260 {
+		t.Errorf("no tunneling: %v", c)
+	}
+
+	// sub-stepping: the big cloud absorbs the small cloud
+	w.SimSpeedUp = 10
+	w.SubStepping = true
+	w.Update()
+	if c := w.Clouds(); len(c) != 1 || c[0].Vapor != 104 {
+		t.Errorf("tunneling: %v", c)
+	}
+}
@@ -0,0 +1,96 @@
+package debug
+
+import (
+	"sort"
+	"sync"
+)
+
+// Annotation shows the intent of a bot (see protocol command 'dbug').
+// The GUI draws the annotations on the game board when the debug overlay is on.
+type Annotation struct {
+	Player     string      // set by the server
+	Iteration  uint64      // set by the server
+	Paths      []Path      `json:",omitempty"` // predicted trajectories
+	Candidates []Candidate `json:",omitempty"` // simulated move commands, best first
+	Target     string      `json:",omitempty"` // UID of the target cloud
+	Threats    []Circle    `json:",omitempty"` // dangerous areas
+	Text       string      `json:",omitempty"` // shown above the player
+}
+
+// Path is a predicted trajectory (e.g. of the own cloud after the best move).
+type Path struct {
+	Points []Point
+}
+
+// Point is a position on the game board.
+type Point struct {
+	X float32
+	Y float32
+}
+
+// Candidate is a move command and its score.
+type Candidate struct {
+	X     float32
+	Y     float32
+	Score float64
+}
+
+// Circle is an area on the game board.
+type Circle struct {
+	X      float32
+	Y      float32
+	Radius float32
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// Board keeps the last annotation of every player (thread-safe).
+type Board struct {
+	mux         sync.Mutex
+	annotations map[string]*Annotation
+}
+
+// NewBoard creates an empty board.
+func NewBoard() *Board {
+	return &Board{annotations: make(map[string]*Annotation)}
+}
+
+// Publish replaces the annotation of the player.
+func (b *Board) Publish(a *Annotation) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.annotations[a.Player] = a
+}
+
+// Remove deletes the annotation of the player (e.g. dead or disconnected).
+func (b *Board) Remove(player string) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	delete(b.annotations, player)
+}
+
+// Set replaces all annotations (e.g. with the annotations of a remote server).
+func (b *Board) Set(aa []*Annotation) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.annotations = make(map[string]*Annotation, len(aa))
+	for _, a := range aa {
+		b.annotations[a.Player] = a
+	}
+}
+
+// All returns the annotations of all players (sorted by player).
+func (b *Board) All() []*Annotation {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	ret := make([]*Annotation, 0, len(b.annotations))
+	for _, a := range b.annotations {
+		ret = append(ret, a)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Player < ret[j].Player })
+	return ret
+}
@@ -0,0 +1,27 @@
+package debug
+
+import (
+	"testing"
+)
+
+func TestBoard(t *testing.T) {
+	b := NewBoard()
+	b.Publish(&Annotation{Player: "B", Text: "1"})
+	b.Publish(&Annotation{Player: "A"})
+	b.Publish(&Annotation{Player: "B", Text: "2"}) // replaced
+
+	all := b.All()
+	if len(all) != 2 || all[0].Player != "A" || all[1].Text != "2" {
+		t.Errorf("fail: %+v", all)
+	}
+
+	b.Remove("A")
+	if all := b.All(); len(all) != 1 || all[0].Player != "B" {
+		t.Errorf("fail: %+v", all)
+	}
+
+	b.Set([]*Annotation{{Player: "C"}})
+	if all := b.All(); len(all) != 1 || all[0].Player != "C" {
+		t.Errorf("fail: %+v", all)
+	}
+}
@@ -0,0 +1,55 @@
+"""
+This file contains an example in Python for the reinforcement learning environment (see package rlenv).
+The environment runs as subprocess and is driven with one json line per command:
+
+    CloudWars -mode rlenv -env env.json
+
+The class has the same methods as a gym environment (reset, step, close),
+so it can be wrapped with gym.Env for RL libraries.
+"""
+
+import json
+import random
+import subprocess
+
+
+class CloudWarsEnv:
+    def __init__(self, cmd=("./CloudWars", "-mode", "rlenv")):
+        self.proc = subprocess.Popen(cmd, stdin=subprocess.PIPE, stdout=subprocess.PIPE, text=True)
+        spaces = self._call({"Cmd": "spaces"})
+        self.observation_space = spaces["ObservationSpace"]
+        self.action_space = spaces["ActionSpace"]
+
+    def _call(self, req):
+        self.proc.stdin.write(json.dumps(req) + "\n")
+        self.proc.stdin.flush()
+        res = json.loads(self.proc.stdout.readline())
+        if "Error" in res:
+            raise RuntimeError(res["Error"])
+        return res
+
+    def reset(self, seed=0):
+        res = self._call({"Cmd": "reset", "Seed": seed})
+        return res["Observation"], res["Info"]
+
+    def step(self, action):
+        # missing values are zero (Reward) or false (Done)
+        res = self._call({"Cmd": "step", "Action": action})
+        return res["Observation"], res.get("Reward", 0), res.get("Done", False), res["Info"]
+
+    def close(self):
+        self._call({"Cmd": "close"})
+        self.proc.wait()
+
+
+if __name__ == "__main__":
+    env = CloudWarsEnv()
+    obs, info = env.reset(seed=1)
+    total, done = 0, False
+    while not done:
+        # random agent (discrete action space)
+        action = random.randrange(env.action_space["N"]) if random.random() < 0.1 else 0
+        obs, reward, done, info = env.step(action)
+        total += reward
+    print("reward", total, "info", info)
+    env.close()
@@ -0,0 +1,189 @@
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"image/color"
+	"math"
+)
+
+const (
+	zoomStep    = 1.1 // per notch of the mouse wheel
+	zoomMax     = 8   // 8 screen pixels per world unit
+	minimapSize = 200 // width or height of the minimap in screen pixels
+)
+
+// camera is the view of the screen on the world: the world position in the center of the screen and the zoom
+// (screen pixels per world unit).
+//
+// The mouse wheel zooms at the cursor, the right mouse button (or the left without a local player) drags the view,
+// key F follows the local player, key H shows the whole world and key M toggles the minimap.
+type camera struct {
+	x, y    float64 // world position in the center of the screen
+	zoom    float64
+	fit     bool // whole world (until zoom or drag)
+	follow  bool // local player in the center
+	minimap bool
+
+	width, height      float64 // screen size
+	worldW, worldH     float64 // world size
+	dragging           bool
+	dragX, dragY       int // last cursor position of the drag
+	minimapX, minimapY float64
+	minimapW, minimapH float64
+	minimapDrag        bool // left mouse button pressed on the minimap
+}
+
+// newCamera creates a camera that shows the whole world.
+func newCamera(worldWidth, worldHeight int) *camera {
+	return &camera{
+		x:       float64(worldWidth) / 2,
+		y:       float64(worldHeight) / 2,
+		zoom:    1,
+		fit:     true,
+		minimap: true,
+		worldW:  float64(worldWidth),
+		worldH:  float64(worldHeight),
+	}
+}
+
+// layout sets the screen size (see Game.Layout).
+func (c *camera) layout(width, height int) {
+	c.width, c.height = float64(width), float64(height)
+	if c.fit {
+		c.showAll()
+	}
+	c.minimapW, c.minimapH = minimapSize, minimapSize*c.worldH/c.worldW
+	if c.minimapH > minimapSize {
+		c.minimapW, c.minimapH = minimapSize*c.worldW/c.worldH, minimapSize
+	}
+	c.minimapX, c.minimapY = c.width-c.minimapW-10, c.height-c.minimapH-10
+}
+
+// showAll centers the world and zooms out until the whole world is visible.
+func (c *camera) showAll() {
+	c.x, c.y = c.worldW/2, c.worldH/2
+	c.zoom = math.Min(c.width/c.worldW, c.height/c.worldH)
+	c.fit = true
+}
+
+// update handles the mouse and keyboard input of the camera. Is the player set, the view follows its position.
+// It returns true if the left mouse button is used by the camera (no move command).
+func (c *camera) update(playerX, playerY float64, player, observer bool) bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) && player {
+		c.follow = !c.follow
+		c.fit = false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		c.follow = false
+		c.showAll()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		c.minimap = !c.minimap
+	}
+
+	// zoom at the cursor (the world position under the cursor stays)
+	cx, cy := ebiten.CursorPosition()
+	if _, dy := ebiten.Wheel(); dy != 0 {
+		wx, wy := c.toWorld(cx, cy)
+		c.zoom = math.Max(math.Min(c.zoom*math.Pow(zoomStep, dy), zoomMax), c.minZoom())
+		c.x = wx - (float64(cx)-c.width/2)/c.zoom
+		c.y = wy - (float64(cy)-c.height/2)/c.zoom
+		c.fit = false
+	}
+
+	// minimap: click or drag to center the view
+	used := false
+	left := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		c.minimapDrag = c.minimap && c.onMinimap(cx, cy)
+	}
+	if left && c.minimapDrag {
+		c.x = (float64(cx) - c.minimapX) / c.minimapW * c.worldW
+		c.y = (float64(cy) - c.minimapY) / c.minimapH * c.worldH
+		c.follow, c.fit = false, false
+		used = true
+	}
+
+	// drag-pan with the right mouse button (observer: also the left mouse button)
+	drag := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) || (observer && left && !used)
+	if drag && c.dragging {
+		c.x -= float64(cx-c.dragX) / c.zoom
+		c.y -= float64(cy-c.dragY) / c.zoom
+		if cx != c.dragX || cy != c.dragY {
+			c.follow, c.fit = false, false
+		}
+	}
+	c.dragging, c.dragX, c.dragY = drag, cx, cy
+
+	// follow the player
+	if c.follow && player {
+		c.x, c.y = playerX, playerY
+	}
+
+	// keep the center on the world
+	c.x = math.Max(0, math.Min(c.x, c.worldW))
+	c.y = math.Max(0, math.Min(c.y, c.worldH))
+	return used
+}
+
+// minZoom is half the zoom of the whole world.
+func (c *camera) minZoom() float64 {
+	return math.Min(c.width/c.worldW, c.height/c.worldH) / 2
+}
+
+// toWorld converts a screen position (e.g. the cursor) to a world position.
+func (c *camera) toWorld(sx, sy int) (float64, float64) {
+	return (float64(sx)-c.width/2)/c.zoom + c.x, (float64(sy)-c.height/2)/c.zoom + c.y
+}
+
+// toScreen converts a world position to a screen position.
+func (c *camera) toScreen(x, y float64) (float64, float64) {
+	return (x-c.x)*c.zoom + c.width/2, (y-c.y)*c.zoom + c.height/2
+}
+
+// geoM is the transformation of world images to the screen.
+func (c *camera) geoM() ebiten.GeoM {
+	var m ebiten.GeoM
+	m.Translate(-c.x, -c.y)
+	m.Scale(c.zoom, c.zoom)
+	m.Translate(c.width/2, c.height/2)
+	return m
+}
+
+// visible checks if a circle of the world is on the screen.
+func (c *camera) visible(x, y, radius float64) bool {
+	sx, sy := c.toScreen(x, y)
+	r := radius * c.zoom
+	return sx+r >= 0 && sy+r >= 0 && sx-r <= c.width && sy-r <= c.height
+}
+
+// line draws a line between two world positions.
+func (c *camera) line(screen *ebiten.Image, x0, y0, x1, y1 float64, clr color.Color) {
+	sx0, sy0 := c.toScreen(x0, y0)
+	sx1, sy1 := c.toScreen(x1, y1)
+	ebitenutil.DrawLine(screen, sx0, sy0, sx1, sy1, clr)
+}
+
+// circle draws the outline of a circle of the world.
+func (c *camera) circle(screen *ebiten.Image, x, y, radius float64, clr color.Color) {
+	const segments = 32
+	for i := 0; i < segments; i++ {
+		a0 := 2 * math.Pi * float64(i) / segments
+		a1 := 2 * math.Pi * float64(i+1) / segments
+		c.line(screen, x+math.Cos(a0)*radius, y+math.Sin(a0)*radius, x+math.Cos(a1)*radius, y+math.Sin(a1)*radius, clr)
+	}
+}
+
+// print draws a text centered at a world position and dy screen pixels below (char width is 6px).
+func (c *camera) print(screen *ebiten.Image, text string, x, y float64, dy int) {
+	sx, sy := c.toScreen(x, y)
+	ebitenutil.DebugPrintAt(screen, text, int(sx)-6/2*len(text), int(sy)+dy)
+}
+
+// onMinimap checks if a screen position is on the minimap.
+func (c *camera) onMinimap(sx, sy int) bool {
+	x, y := float64(sx), float64(sy)
+	return x >= c.minimapX && y >= c.minimapY && x <= c.minimapX+c.minimapW && y <= c.minimapY+c.minimapH
+}
@@ -0,0 +1,79 @@
+package gui
+
+import (
+	"CloudWars/core"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"image/color"
+	"math"
+)
+
+// playerColors are the overlay colors of the cloud colors
+var playerColors = map[string]color.RGBA{
+	"blue":   {R: 90, G: 150, B: 255, A: 255},
+	"orange": {R: 255, G: 160, B: 40, A: 255},
+	"purple": {R: 190, G: 110, B: 255, A: 255},
+	"red":    {R: 255, G: 80, B: 80, A: 255},
+	"gray":   {R: 200, G: 200, B: 200, A: 255},
+}
+
+// drawDebug draws the annotations of the bots (see debug.Annotation):
+// predicted paths, candidate moves (best first), the target and the threats.
+func (g *Game) drawDebug(screen *ebiten.Image) {
+	if g.board == nil {
+		return
+	}
+	clouds := make(map[string]*core.Cloud)
+	for _, c := range g.world.Clouds() {
+		clouds[c.UID] = c
+	}
+
+	for _, a := range g.board.All() {
+		me := g.world.Me(a.Player)
+		if me == nil || me.IsDeath() {
+			continue
+		}
+		clr, ok := playerColors[me.Color]
+		if !ok {
+			clr = playerColors["gray"]
+		}
+		x, y := float64(me.Pos.X), float64(me.Pos.Y)
+
+		// threats and target
+		for _, t := range a.Threats {
+			g.camera.circle(screen, float64(t.X), float64(t.Y), float64(t.Radius), color.RGBA{R: 255, G: 40, B: 40, A: 160})
+		}
+		if t, ok := clouds[a.Target]; ok {
+			g.camera.circle(screen, float64(t.Pos.X), float64(t.Pos.Y), float64(t.Radius()+6), color.RGBA{R: 255, G: 230, B: 0, A: 220})
+		}
+
+		// predicted paths
+		for _, p := range a.Paths {
+			for i := 1; i < len(p.Points); i++ {
+				p0, p1 := p.Points[i-1], p.Points[i]
+				g.camera.line(screen, float64(p0.X), float64(p0.Y), float64(p1.X), float64(p1.Y), clr)
+			}
+		}
+
+		// candidates: the direction and strength of the move, the best one is bright
+		for i, c := range a.Candidates {
+			strength := math.Hypot(float64(c.X), float64(c.Y))
+			if strength < 1 {
+				continue // no move
+			}
+			length := float64(me.Radius()) + 20 + math.Min(strength, 300)/3
+			cc := clr
+			if i > 0 {
+				cc.A = 90
+			}
+			g.camera.line(screen, x, y, x+float64(c.X)/strength*length, y+float64(c.Y)/strength*length, cc)
+		}
+
+		// text
+		txt := a.Text
+		if len(a.Candidates) > 0 {
+			txt = fmt.Sprintf("%s (best %.0f)", txt, a.Candidates[0].Score)
+		}
+		g.camera.print(screen, txt, x, y-float64(me.Radius()), -18)
+	}
+}
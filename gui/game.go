@@ -2,16 +2,20 @@ package gui
 
 import (
 	"CloudWars/core"
+	"CloudWars/debug"
 	"CloudWars/remote"
+	"CloudWars/stats"
 	"errors"
 	"fmt"
 	"github.com/golang/freetype/truetype"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font/gofont/gomonobold"
 	"image"
 	"image/color"
+	"math"
 	"time"
 )
 
@@ -19,21 +23,34 @@ import (
 var _ ebiten.Game = (*Game)(nil)
 
 type Game struct {
-	screenWidth       int
+	screenWidth       int // window size (see Layout)
 	screenHeight      int
+	camera            *camera // view on the world (zoom, pan, follow, minimap)
 	world             *core.World
 	localPlayer       *core.Cloud // manual control for this player cloud
 	maxUpdateTime     time.Duration
 	externWorldUpdate bool
 	remoteMove        *remote.TcpClient
+	showWind          bool             // toggle with key W
+	showDebug         bool             // toggle with key D
+	showPrediction    bool             // toggle with key P
+	prediction        *prediction      // trajectory overlay (see predict)
+	ticks             int              // calls of Update
+	board             *debug.Board     // annotations of the bots (debug overlay)
+	rewind            bool             // rewind with key backspace
+	stats             *stats.Collector // summary screen at game end (local world only)
 }
 
 // RunGame creates a GUI. The game can be watched in the window or a player cloud can be controlled with the mouse.
-// title is for the window title. screenWidth and screenHeight define the dimensions of the window.
+// title is for the window title. screenWidth and screenHeight define the initial size of the window (at most the
+// monitor size). The camera shows the whole world, it zooms with the mouse wheel and pans with the right mouse button.
 // The window can also update the game logic of world. gameSpeed defines how often an update is called per second.
 // Is externWorldUpdate true, no game logic is updated. If a local cloud is set with localPlayer, it can be controlled
 // with the mouse. Is remoteMove set, the move command is send to a remote server with remote.TcpClient.
-func RunGame(title string, screenWidth, screenHeight, gameSpeed int, world *core.World, localPlayer *core.Cloud, externWorldUpdate bool, remoteMove *remote.TcpClient) error {
+// Is rewind true, the world can be rewound with backspace (see core.World EnableHistory).
+// The annotations of the bots on the board are shown with key D (see debug.Annotation).
+// The predicted paths of the local player and the nearby clouds are shown with key P.
+func RunGame(title string, screenWidth, screenHeight, gameSpeed int, world *core.World, localPlayer *core.Cloud, externWorldUpdate bool, remoteMove *remote.TcpClient, rewind bool, board *debug.Board) error {
 	// world check
 	if world == nil {
 		return errors.New("world is nul")
@@ -43,16 +60,27 @@ func RunGame(title string, screenWidth, screenHeight, gameSpeed int, world *core
 	game := &Game{
 		screenWidth:       screenWidth,
 		screenHeight:      screenHeight,
+		camera:            newCamera(world.Width(), world.Height()),
 		world:             world,
 		localPlayer:       localPlayer,
 		maxUpdateTime:     0, // 16ms is fast enough for 60 updates per second
 		externWorldUpdate: externWorldUpdate,
 		remoteMove:        remoteMove,
+		rewind:            rewind,
+		board:             board,
+	}
+	if remoteMove == nil {
+		game.stats = stats.NewCollector(world)
 	}
 
 	// config window
 	ebiten.SetWindowTitle(title)
 	ebiten.SetWindowIcon([]image.Image{logoImage})
+	if w, h := ebiten.ScreenSizeInFullscreen(); w > 0 && h > 0 {
+		// fit the window on the monitor (same aspect ratio)
+		scale := math.Min(1, math.Min(float64(w)*0.9/float64(screenWidth), float64(h)*0.9/float64(screenHeight)))
+		screenWidth, screenHeight = int(float64(screenWidth)*scale), int(float64(screenHeight)*scale)
+	}
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowResizable(true)
 	ebiten.SetMaxTPS(gameSpeed) // default: 60
@@ -80,7 +108,13 @@ func RunGame(title string, screenWidth, screenHeight, gameSpeed int, world *core
 //
 // You can return a fixed screen size if you don't care, or you can also return a calculated screen size
 // adjusted with the given outside size.
-func (g *Game) Layout(_, _ int) (int, int) {
+//
+// The screen has the size of the window, the camera maps the world on it.
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if outsideWidth > 0 && outsideHeight > 0 {
+		g.screenWidth, g.screenHeight = outsideWidth, outsideHeight
+	}
+	g.camera.layout(g.screenWidth, g.screenHeight)
 	return g.screenWidth, g.screenHeight
 }
 
@@ -96,19 +130,49 @@ func (g *Game) Layout(_, _ int) (int, int) {
 func (g *Game) Update() error {
 	start := time.Now()
 
+	// toggle overlays
+	if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		g.showWind = !g.showWind
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		g.showDebug = !g.showDebug
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.showPrediction = !g.showPrediction
+		g.prediction = nil
+	}
+
+	// camera (follow the local player)
+	var me *core.Cloud
+	if g.localPlayer != nil {
+		me = g.world.Me(g.localPlayer.Player)
+	}
+	var used bool
+	if me != nil && !me.IsDeath() {
+		used = g.camera.update(float64(me.Pos.X), float64(me.Pos.Y), true, false)
+	} else {
+		used = g.camera.update(0, 0, false, g.localPlayer == nil)
+	}
+
+	// rewind with backspace (local world with history)
+	if g.rewind && !g.externWorldUpdate && ebiten.IsKeyPressed(ebiten.KeyBackspace) {
+		iteration, _, _, _, _ := g.world.Stats()
+		if iteration >= 2 {
+			g.world.Rewind(iteration - 2) // twice as fast as the game
+		}
+		return nil
+	}
+
 	// player control
-	if g.localPlayer != nil && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		x, y := ebiten.CursorPosition()
+	if g.localPlayer != nil && !used && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		if g.remoteMove == nil {
 			// local command
 			c := g.localPlayer
-			v := core.NewVelocity((float32(x)-c.Pos.X)/100, (float32(y)-c.Pos.Y)/100)
-			g.world.Move(c, v)
+			g.world.Move(c, g.cursorWind(c))
 		} else {
 			// remote command
 			c := g.world.Me(g.localPlayer.Player)
-			v := core.NewVelocity((float32(x)-c.Pos.X)/100, (float32(y)-c.Pos.Y)/100)
-			g.remoteMove.Move(v)
+			g.remoteMove.Move(g.cursorWind(c))
 		}
 	}
 
@@ -117,6 +181,14 @@ func (g *Game) Update() error {
 		g.world.Update()
 	}
 
+	// trajectory forecast (none without a living player)
+	g.ticks++
+	if me == nil || me.IsDeath() {
+		g.prediction = nil
+	} else if g.showPrediction && g.ticks%predictInterval == 0 {
+		g.prediction = g.predict()
+	}
+
 	// watch maxUpdateTime
 	duration := time.Since(start)
 	if g.maxUpdateTime.Microseconds() < duration.Microseconds() {
@@ -129,20 +201,47 @@ func (g *Game) Update() error {
 	return nil
 }
 
+// cursorWind returns the move command of a cloud toward the cursor (the farther, the stronger).
+// The distance is measured in the world (see camera).
+func (g *Game) cursorWind(c *core.Cloud) *core.Velocity {
+	x, y := g.camera.toWorld(ebiten.CursorPosition())
+	return core.NewVelocity((float32(x)-c.Pos.X)/100, (float32(y)-c.Pos.Y)/100)
+}
+
 // Draw draws the game screen by one frame.
 //
 // The give argument represents a screen image. The updated content is adopted as the game screen.
 func (g *Game) Draw(screen *ebiten.Image) {
-	// background image
+	cam := g.camera
+	camGeoM := cam.geoM()
+
+	// background image (world size)
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Scale(float64(g.screenWidth)/1273.0, float64(g.screenHeight)/720.0) // bgImage is 1273px * 720px
-	op.Filter = ebiten.FilterLinear                                             // Specify linear filter.
+	op.GeoM.Scale(float64(g.world.Width())/1273.0, float64(g.world.Height())/720.0) // bgImage is 1273px * 720px
+	op.GeoM.Concat(camGeoM)
+	op.Filter = ebiten.FilterLinear // Specify linear filter.
 	screen.DrawImage(bgImage, op)
 
+	// power-ups
+	for _, i := range g.world.Items() {
+		if img, ok := itemImages[i.Kind]; ok {
+			size := float64(core.ItemRadius*2) / 30 // the image is 32px, but the disc is 30px
+			op = &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(size, size)
+			op.GeoM.Translate(float64(i.Pos.X)-16*size, float64(i.Pos.Y)-16*size)
+			op.GeoM.Concat(camGeoM)
+			op.Filter = ebiten.FilterLinear // Specify linear filter.
+			screen.DrawImage(img, op)
+		}
+	}
+
 	// cloud images
 	for _, c := range g.world.Clouds() {
 		// calc for image placing
 		radius := c.Radius()
+		if !cam.visible(float64(c.Pos.X), float64(c.Pos.Y), float64(radius*1.28)) {
+			continue
+		}
 		size := radius * 2 / 400 // the image is 512px, but the ball is 400px
 		off := radius * 1.28     // 512px / 400px = 1.28
 
@@ -150,6 +249,7 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		op = &ebiten.DrawImageOptions{}
 		op.GeoM.Scale(float64(size), float64(size))
 		op.GeoM.Translate(float64(c.Pos.X-off), float64(c.Pos.Y-off))
+		op.GeoM.Concat(camGeoM)
 		op.Filter = ebiten.FilterLinear // Specify linear filter.
 
 		// select color
@@ -171,13 +271,43 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		// char width is 6px
 		name := c.Player
 		if name != "" && !c.IsDeath() {
-			ebitenutil.DebugPrintAt(screen, name, int(c.Pos.X-float32(6/2*len(name))), int(c.Pos.Y+5+radius))
+			cam.print(screen, name, float64(c.Pos.X), float64(c.Pos.Y+radius), 5)
+
+			// active power-ups
+			for n, e := range c.Effects {
+				effect := fmt.Sprintf("%s %.0fs", e.Kind, math.Ceil(float64(e.Ticks)/float64(g.world.GameSpeed())))
+				cam.print(screen, effect, float64(c.Pos.X), float64(c.Pos.Y+radius), 5+12*(n+1))
+			}
 		}
 	}
 
+	// wind arrows
+	if g.showWind {
+		g.drawWind(screen)
+	}
+
+	// trajectory forecast
+	if g.showPrediction {
+		g.drawPrediction(screen)
+	}
+
+	// bot annotations
+	if g.showDebug {
+		g.drawDebug(screen)
+	}
+
+	// minimap
+	if cam.minimap {
+		g.drawMinimap(screen)
+	}
+
 	// DEBUG text
 	iteration, worldVapor, alive, winCondition, leader := g.world.Stats()
 	msg := fmt.Sprintf("\n  round=%d/%d, alive=%d, worldVapor=%.0f, maxUpdateTime=%v\n", iteration, g.world.MaxIterations(), alive, worldVapor, g.maxUpdateTime)
+	if weather := g.world.Rules().Weather; weather.EvaporationRate > 0 || weather.RainInterval > 0 {
+		evaporated, rained := g.world.VaporBalance()
+		msg += fmt.Sprintf("  evaporated=%.0f, rained=%.0f\n", evaporated, rained)
+	}
 	for _, c := range g.world.Clouds() {
 		if c.Player != "" {
 			if !c.IsDeath() {
@@ -198,10 +328,110 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		face := truetype.NewFace(fnt, &truetype.Options{Size: 44})
 
 		winnerMsg := fmt.Sprintf("Victory: %s", leader)
-		x := g.world.Width()/2 - 24/2*len(winnerMsg) - 20
-		y := g.world.Height()/2 - 32/2
+		x := g.screenWidth/2 - 24/2*len(winnerMsg) - 20
+		y := g.screenHeight/2 - 32/2
 		clr := color.White
 
 		text.Draw(screen, winnerMsg, face, x, y, clr)
+
+		// summary screen
+		if g.stats != nil {
+			g.drawSummary(screen, y+32)
+		}
+	}
+}
+
+// drawSummary draws the statistics of all players below the victory message.
+func (g *Game) drawSummary(screen *ebiten.Image, y int) {
+	r := g.stats.Report()
+	lines := []string{fmt.Sprintf("%-16s %7s %7s %7s %9s %9s %5s %6s %8s %6s", "player", "final", "peak", "spent", "neutral", "players", "kills", "deaths", "distance", "moves")}
+	for _, p := range r.Players {
+		lines = append(lines, fmt.Sprintf("%-16.16s %7.0f %7.0f %7.0f %9.0f %9.0f %5d %6d %8.0f %6d",
+			p.Name, p.FinalVapor, p.PeakVapor, p.Spent, p.AbsorbedNeutral, p.AbsorbedPlayer, p.Kills, p.Deaths, p.Distance, p.Moves))
+	}
+	lines = append(lines, fmt.Sprintf("duration: %.0f s", r.Seconds))
+
+	// background
+	// char height is 16px (line)
+	// char width is 6px
+	width := 6*len(lines[0]) + 20
+	height := 16*len(lines) + 10
+	x := g.screenWidth/2 - width/2
+	ebitenutil.DrawRect(screen, float64(x), float64(y), float64(width), float64(height), color.RGBA{A: 160})
+
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x+10, y+5+16*i)
+	}
+}
+
+// drawWind draws the wind fields of the world as arrows.
+func (g *Game) drawWind(screen *ebiten.Image) {
+	if len(g.world.Rules().Wind) == 0 {
+		return // no wind
+	}
+
+	const step = 96 // distance between the arrows
+	clr := color.RGBA{R: 255, G: 255, B: 255, A: 120}
+
+	for y := step / 2; y < g.world.Height(); y += step {
+		for x := step / 2; x < g.world.Width(); x += step {
+			if !g.camera.visible(float64(x), float64(y), step) {
+				continue
+			}
+			v := g.world.WindAt(float32(x), float32(y))
+			strength := float64(v.Strength())
+			if strength < 0.01 {
+				continue // calm
+			}
+
+			// arrow length: max 80% of step
+			length := math.Min(strength*8, step*0.8)
+			dx := float64(v.X) / strength * length
+			dy := float64(v.Y) / strength * length
+			x0, y0 := float64(x)-dx/2, float64(y)-dy/2
+			x1, y1 := float64(x)+dx/2, float64(y)+dy/2
+
+			// shaft and head
+			g.camera.line(screen, x0, y0, x1, y1, clr)
+			angle := math.Atan2(dy, dx)
+			for _, a := range []float64{angle + 2.6, angle - 2.6} {
+				g.camera.line(screen, x1, y1, x1+math.Cos(a)*length/3, y1+math.Sin(a)*length/3, clr)
+			}
+		}
+	}
+}
+
+// drawMinimap draws the whole world in a corner of the screen: the clouds as dots and the view of the camera as frame.
+// A click on the minimap moves the view (see camera).
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+	cam := g.camera
+	scale := cam.minimapW / float64(g.world.Width())
+	ebitenutil.DrawRect(screen, cam.minimapX, cam.minimapY, cam.minimapW, cam.minimapH, color.RGBA{A: 160})
+
+	// clouds (at least 1px)
+	for _, c := range g.world.Clouds() {
+		if c.IsDeath() {
+			continue
+		}
+		clr := color.RGBA{R: 160, G: 160, B: 160, A: 200}
+		if c.Player != "" {
+			if clr = playerColors[c.Color]; clr.A == 0 {
+				clr = playerColors["gray"]
+			}
+		}
+		size := math.Max(1, float64(c.Radius())*2*scale)
+		ebitenutil.DrawRect(screen, cam.minimapX+float64(c.Pos.X)*scale-size/2, cam.minimapY+float64(c.Pos.Y)*scale-size/2, size, size, clr)
 	}
+
+	// view of the camera
+	x0, y0 := cam.toWorld(0, 0)
+	x1, y1 := cam.toWorld(g.screenWidth, g.screenHeight)
+	x0, y0 = math.Max(0, x0)*scale, math.Max(0, y0)*scale
+	x1, y1 = math.Min(float64(g.world.Width()), x1)*scale, math.Min(float64(g.world.Height()), y1)*scale
+	clr := color.RGBA{R: 255, G: 255, B: 255, A: 200}
+	mx, my := cam.minimapX, cam.minimapY
+	ebitenutil.DrawLine(screen, mx+x0, my+y0, mx+x1, my+y0, clr)
+	ebitenutil.DrawLine(screen, mx+x1, my+y0, mx+x1, my+y1, clr)
+	ebitenutil.DrawLine(screen, mx+x1, my+y1, mx+x0, my+y1, clr)
+	ebitenutil.DrawLine(screen, mx+x0, my+y1, mx+x0, my+y0, clr)
 }
@@ -1,11 +1,15 @@
 package gui
 
 import (
+	"CloudWars/core"
 	"embed"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"image"
+	"image/color"
 	_ "image/png" // needed for NewImageFromFile()
 	"log"
+	"math"
 )
 
 var (
@@ -16,6 +20,7 @@ var (
 	orangeImage *ebiten.Image
 	purpleImage *ebiten.Image
 	redImage    *ebiten.Image
+	itemImages  map[string]*ebiten.Image // power-ups (see core.Item)
 )
 
 //go:embed images
@@ -29,6 +34,28 @@ func init() {
 	orangeImage = load("images/orange.png")
 	purpleImage = load("images/purple.png")
 	redImage = load("images/red.png")
+
+	// power-ups: colored disc with a white symbol
+	itemImages = map[string]*ebiten.Image{
+		// arrow
+		core.ItemSpeed: newItemImage(color.RGBA{R: 240, G: 200, B: 30, A: 255}, func(x, y float64) bool {
+			return x >= -5 && x <= 7 && math.Abs(y) <= (7-x)/2
+		}),
+		// ring
+		core.ItemShield: newItemImage(color.RGBA{R: 40, G: 190, B: 230, A: 255}, func(x, y float64) bool {
+			r := math.Hypot(x, y)
+			return r >= 5 && r <= 8
+		}),
+		// plus
+		core.ItemFree: newItemImage(color.RGBA{R: 60, G: 190, B: 80, A: 255}, func(x, y float64) bool {
+			return (math.Abs(x) <= 2 && math.Abs(y) <= 7) || (math.Abs(y) <= 2 && math.Abs(x) <= 7)
+		}),
+		// horseshoe
+		core.ItemMagnet: newItemImage(color.RGBA{R: 210, G: 50, B: 190, A: 255}, func(x, y float64) bool {
+			r := math.Hypot(x, y)
+			return (y >= 0 && r >= 4 && r <= 8) || (y < 0 && y >= -7 && math.Abs(x) >= 4 && math.Abs(x) <= 8)
+		}),
+	}
 }
 
 func load(name string) *ebiten.Image {
@@ -45,3 +72,26 @@ func load(name string) *ebiten.Image {
 	// return
 	return eim
 }
+
+// newItemImage draws a 32px power-up sprite: a colored disc (radius 15) with a white symbol.
+// symbol is called with the coordinates relative to the center.
+func newItemImage(clr color.RGBA, symbol func(x, y float64) bool) *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	border := color.RGBA{R: clr.R / 2, G: clr.G / 2, B: clr.B / 2, A: 255}
+	for py := 0; py < 32; py++ {
+		for px := 0; px < 32; px++ {
+			x, y := float64(px)-15.5, float64(py)-15.5
+			switch r := math.Hypot(x, y); {
+			case r > 15:
+				// transparent
+			case r > 13:
+				img.Set(px, py, border)
+			case symbol(x, y):
+				img.Set(px, py, color.White)
+			default:
+				img.Set(px, py, clr)
+			}
+		}
+	}
+	return ebiten.NewImageFromImage(img)
+}
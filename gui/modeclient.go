@@ -2,6 +2,7 @@ package gui
 
 import (
 	"CloudWars/core"
+	"CloudWars/debug"
 	"CloudWars/remote"
 	"log"
 	"os"
@@ -27,11 +28,13 @@ func ModeClientGUI(host, port string, observer bool, name, color string) {
 	tcpClient := remote.NewTcpClient(host, port)
 	cWorld := new(core.World)
 
-	// CLIENT update loop
+	// CLIENT update loop (world and bot annotations)
+	board := debug.NewBoard()
 	go func() {
 		for {
 			time.Sleep(100 * time.Millisecond)
 			cWorld.FromJson(tcpClient.List())
+			board.Set(tcpClient.Annotations())
 		}
 	}()
 
@@ -68,7 +71,7 @@ func ModeClientGUI(host, port string, observer bool, name, color string) {
 	}
 
 	// CLIENT GUI
-	if err := RunGame(title, cWorld.Width(), cWorld.Height(), cWorld.GameSpeed(), cWorld, me, false, tcpClient); err != nil {
+	if err := RunGame(title, cWorld.Width(), cWorld.Height(), cWorld.GameSpeed(), cWorld, me, false, tcpClient, false, board); err != nil {
 		log.Fatalf("ModeClientGUI: %v\n", err)
 	}
 
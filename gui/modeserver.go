@@ -2,6 +2,7 @@ package gui
 
 import (
 	"CloudWars/core"
+	"CloudWars/debug"
 	"CloudWars/remote"
 	"fmt"
 	"log"
@@ -26,6 +27,10 @@ import (
 //    neutralMaxSpeed: random [0 to n] initial speed (DEFAULT: 7)
 //    neutralMaxVapor: random [0-n] vapor for neutral objects (DEFAULT: 200)
 //
+// rules
+//    rules: optional game rules (DEFAULT: classic game)
+//    lag: lag compensation window in milliseconds for move commands (DEFAULT: 0 = off)
+//
 // remote player (server)
 //    remotePlayer: enable remote player
 //    remoteAmount: wait for n remote player
@@ -34,19 +39,30 @@ import (
 //    localPlayer: enable local player (false: server mode only)
 //    localName: name for local player
 //    localColor: color for local player ('blue', 'gray', 'orange', 'purple' or 'red')
-func ModeServerGUI(host, port string, screenWidth, screenHeight, gameSpeed int, playerVapor float32, neutralAmount int, neutralMaxSpeed, neutralMaxVapor float32, remotePlayer bool, remoteAmount int, localPlayer bool, localName, localColor string) {
+//    rewind: rewind the last 10 seconds with backspace (local play only, a snapshot per update)
+func ModeServerGUI(host, port string, screenWidth, screenHeight, gameSpeed int, playerVapor float32, neutralAmount int, neutralMaxSpeed, neutralMaxVapor float32, rules core.Rules, lag int, remotePlayer bool, remoteAmount int, localPlayer bool, localName, localColor string, rewind bool) {
 
 	// init
 	sWorld := core.NewWorld(screenWidth, screenHeight, gameSpeed, neutralAmount, neutralMaxSpeed, neutralMaxVapor, time.Now().UnixMicro())
+	sWorld.SetRules(rules)
 
 	var lPlayer *core.Cloud
 	if localPlayer {
 		lPlayer = sWorld.AddPlayer(localName, localColor, nil, playerVapor)
 	}
 
-	// run server
+	// history: lag compensation or rewind with backspace (local play, last 10 seconds)
+	rewind = rewind && localPlayer && !remotePlayer
+	if rewind {
+		sWorld.EnableHistory(gameSpeed * 10)
+	} else if lag > 0 {
+		sWorld.EnableHistory(lag * gameSpeed / 1000)
+	}
+
+	// run server (the GUI draws the annotations of its bots)
+	board := debug.NewBoard()
 	if remotePlayer {
-		go remote.RunServer(host, port, playerVapor, sWorld, remoteAmount)
+		go remote.RunServer(host, port, playerVapor, sWorld, remoteAmount, board)
 	}
 
 	// generate title
@@ -62,7 +78,7 @@ func ModeServerGUI(host, port string, screenWidth, screenHeight, gameSpeed int,
 	}
 
 	// SERVER GUI
-	if err := RunGame(title, screenWidth, screenHeight, gameSpeed, sWorld, lPlayer, false, nil); err != nil {
+	if err := RunGame(title, screenWidth, screenHeight, gameSpeed, sWorld, lPlayer, false, nil, rewind, board); err != nil {
 		log.Fatalf("ModeServerGUI: %v\n", err)
 	}
 
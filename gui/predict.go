@@ -0,0 +1,154 @@
+package gui
+
+import (
+	"CloudWars/core"
+	"github.com/hajimehoshi/ebiten/v2"
+	"image/color"
+	"math"
+)
+
+const (
+	predictSeconds  = 3   // forecast of the trajectory overlay
+	predictRange    = 400 // nearby clouds (distance between the edges)
+	predictSpeedUp  = 5   // game ticks per simulation step
+	predictInterval = 6   // game ticks between two forecasts
+)
+
+// trajectory is the predicted path of a cloud.
+type trajectory struct {
+	uid    string
+	points []core.Position
+	radius float32 // at the end of the path
+}
+
+// prediction is the forecast of the trajectory overlay (toggle with key P).
+type prediction struct {
+	paths   []*trajectory // local player (first) and nearby clouds without a move
+	preview []*trajectory // local player (first) and exhaust cloud after the move at the cursor (nil: invalid move)
+}
+
+// predict forecasts the paths of the local player and the nearby clouds on a clone of the world.
+// The preview is the same forecast after the move the cursor would produce now.
+func (g *Game) predict() *prediction {
+	me := g.world.Me(g.localPlayer.Player)
+	if me == nil || me.IsDeath() {
+		return nil
+	}
+
+	// clouds to forecast
+	track := map[string]bool{me.UID: true}
+	for _, c := range g.world.Clouds() {
+		if !c.IsDeath() && c.UID != me.UID && distance(me, c) < predictRange {
+			track[c.UID] = true
+		}
+	}
+
+	ret := &prediction{paths: forecast(g.world.Clone(), me.UID, track)}
+
+	// preview of the move (the exhaust is a new cloud)
+	w := g.world.Clone()
+	before := make(map[string]bool)
+	for _, c := range w.Clouds() {
+		before[c.UID] = true
+	}
+	if w.Move(w.Me(me.Player), g.cursorWind(me)) {
+		track = map[string]bool{me.UID: true}
+		for _, c := range w.Clouds() {
+			if !before[c.UID] {
+				track[c.UID] = true // exhaust
+			}
+		}
+		ret.preview = forecast(w, me.UID, track)
+	}
+	return ret
+}
+
+// forecast updates the world for predictSeconds and returns the paths of the tracked clouds (first: uid).
+// The world is changed.
+func forecast(w *core.World, uid string, track map[string]bool) []*trajectory {
+	w.SimSpeedUp = predictSpeedUp // keeps SubStepping of the world
+
+	paths := make(map[string]*trajectory)
+	var ret []*trajectory
+	record := func() {
+		for _, c := range w.Clouds() {
+			if !track[c.UID] {
+				continue
+			}
+			t, ok := paths[c.UID]
+			if !ok {
+				t = &trajectory{uid: c.UID}
+				paths[c.UID] = t
+				if c.UID == uid {
+					ret = append([]*trajectory{t}, ret...)
+				} else {
+					ret = append(ret, t)
+				}
+			}
+			if !c.IsDeath() {
+				t.points = append(t.points, *c.Pos)
+				t.radius = c.Radius()
+			}
+		}
+	}
+
+	record()
+	for t := 0; t < predictSeconds*w.GameSpeed(); t += predictSpeedUp {
+		w.Update()
+		record()
+	}
+	return ret
+}
+
+// drawPrediction draws the forecast: the paths without a move (dim), the path after the move at the cursor
+// (bright) and the spawn and path of its exhaust cloud.
+func (g *Game) drawPrediction(screen *ebiten.Image) {
+	p := g.prediction
+	if p == nil {
+		return
+	}
+	me := g.world.Me(g.localPlayer.Player)
+	if me == nil {
+		return // dead (removed from the world)
+	}
+	clr, ok := playerColors[me.Color]
+	if !ok {
+		clr = playerColors["gray"]
+	}
+	dim := clr
+	dim.A = 110
+
+	for i, t := range p.paths {
+		if i == 0 {
+			g.camera.trajectory(screen, t, dim)
+		} else {
+			g.camera.trajectory(screen, t, color.RGBA{R: 255, G: 255, B: 255, A: 90})
+		}
+	}
+	for i, t := range p.preview {
+		if i == 0 {
+			g.camera.trajectory(screen, t, clr)
+		} else if len(t.points) > 0 {
+			spawn := t.points[0]
+			g.camera.circle(screen, float64(spawn.X), float64(spawn.Y), float64(t.radius), color.RGBA{R: 200, G: 200, B: 200, A: 200})
+			g.camera.trajectory(screen, t, color.RGBA{R: 200, G: 200, B: 200, A: 140})
+		}
+	}
+}
+
+// trajectory draws the path of a cloud and its outline at the end.
+func (c *camera) trajectory(screen *ebiten.Image, t *trajectory, clr color.Color) {
+	for i := 1; i < len(t.points); i++ {
+		p0, p1 := t.points[i-1], t.points[i]
+		c.line(screen, float64(p0.X), float64(p0.Y), float64(p1.X), float64(p1.Y), clr)
+	}
+	if n := len(t.points); n > 1 {
+		end := t.points[n-1]
+		c.circle(screen, float64(end.X), float64(end.Y), float64(t.radius), clr)
+	}
+}
+
+// distance between the edges of two clouds (negative: overlap)
+func distance(a, b *core.Cloud) float32 {
+	return float32(math.Hypot(float64(b.Pos.X-a.Pos.X), float64(b.Pos.Y-a.Pos.Y))) - a.Radius() - b.Radius()
+}
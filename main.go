@@ -1,10 +1,16 @@
 package main
 
 import (
+	"CloudWars/ai/mcts"
 	"CloudWars/ai/simai"
+	"CloudWars/bot"
 	"CloudWars/core"
 	"CloudWars/gui"
+	"CloudWars/rating"
 	"CloudWars/remote"
+	"CloudWars/rlenv"
+	"CloudWars/stats"
+	"CloudWars/tournament"
 	"bufio"
 	"flag"
 	"fmt"
@@ -18,7 +24,7 @@ import (
 const VERSION = "1.2"
 
 const (
-	descMode            = "Select Mode  ['singleplayer', 'server', 'client' or 'simai']"
+	descMode            = "Select Mode  ['singleplayer', 'server', 'client', 'simai', 'mcts', 'arena', 'tune', 'rlenv', 'rating' or 'tournament']"
 	descHost            = "hostname or ip  [DEFAULT: localhost]"
 	descPort            = "tcp port  [DEFAULT: 3333]"
 	descScreenWidth     = "screen & game board width  [DEFAULT: 2048]"
@@ -28,12 +34,31 @@ const (
 	descNeutralAmount   = "neutral cloud amount  [DEFAULT: 100]"
 	descNeutralMaxSpeed = "neutral cloud max speed  [DEFAULT: 7]"
 	descNeutralMaxVapor = "neutral cloud max vapor  [DEFAULT: 200]"
+	descRules           = "optional game rules file (json)  [DEFAULT: classic game]"
+	descLag             = "lag compensation window in ms for move commands with iteration  [DEFAULT: 0 = off]"
+	descReport          = "headless: write a match report (path.json & path.md) and exit at game end  [DEFAULT: none]"
+	descSeed            = "headless & arena: world seed  [DEFAULT: random]"
+	descTimeout         = "headless: end the game after n seconds (game time)  [DEFAULT: 0 = off]"
+	descLockstep        = "headless: lock-step mode, the world advances when all players have sent an action or after n ms  [DEFAULT: 0 = real time]"
 	descHeadless        = "run server without gui (headless)  [DEFAULT false]"
 	descLocalPlayer     = "enable local player (false = observer)  [DEFAULT: true]"
 	descLocalName       = "local player name"
 	descLocalColor      = "local player color  ['blue', 'gray', 'orange', 'purple' or 'red']"
 	descRemotePlayer    = "enable remote player  [DEFAULT: false]"
 	descRemoteAmount    = "remote player amount  [DEFAULT: 3]"
+	descLedger          = "rating ledger file (json)  [DEFAULT: ratings.json]"
+	descAddReport       = "rating: add the result of a match report (json)  [DEFAULT: none]"
+	descHistory         = "rating: show the history of a player  [DEFAULT: leaderboard]"
+	descTournament      = "tournament config file (json)"
+	descBots            = "arena: number of bots (2-5)  [DEFAULT: 2]"
+	descMCTS            = "arena: number of MCTS bots, the other bots are SimAI bots  [DEFAULT: 0]"
+	descGames           = "arena: number of games  [DEFAULT: 1]"
+	descBudget          = "mcts & arena: thinking time per decision in ms of the MCTS and SimAI bots  [DEFAULT: 0 = MCTS 80 ms, arena SimAI without limit]"
+	descRewind          = "singleplayer & server: rewind the last 10 seconds with backspace (local play only, costs a world snapshot per update)  [DEFAULT: false]"
+	descDebug           = "simai: publish debug annotations for the GUI overlay (costs a simulation per decision)  [DEFAULT: false]"
+	descSimAI           = "simai: strategy config file (json), arena: comma separated list (one per bot)  [DEFAULT: classic SimAI]"
+	descTune            = "tune: evolution config file (json), the best weights are written to a SimAI config  [DEFAULT: defaults]"
+	descEnv             = "rlenv: environment config file (json), line based json on stdin & stdout or on -port  [DEFAULT: defaults]"
 )
 
 func main() {
@@ -49,12 +74,31 @@ func main() {
 	flagNeutralAmount := flag.String("nAmount", "", descNeutralAmount)
 	flagNeutralMaxSpeed := flag.String("nSpeed", "", descNeutralMaxSpeed)
 	flagNeutralMaxVapor := flag.String("nVapor", "", descNeutralMaxVapor)
+	flagRules := flag.String("rules", "", descRules)
+	flagLag := flag.String("lag", "", descLag)
 	flagHeadless := flag.String("headless", "", descHeadless)
+	flagReport := flag.String("report", "", descReport)
+	flagSeed := flag.String("seed", "", descSeed)
+	flagTimeout := flag.String("timeout", "", descTimeout)
+	flagLockstep := flag.String("lockstep", "", descLockstep)
 	flagLocalPlayer := flag.String("lPlayer", "", descLocalPlayer)
 	flagLocalName := flag.String("lName", "", descLocalName)
 	flagLocalColor := flag.String("lColor", "", descLocalColor)
 	flagRemotePlayer := flag.String("rPlayer", "", descRemotePlayer)
 	flagRemoteAmount := flag.String("rAmount", "", descRemoteAmount)
+	flagLedger := flag.String("ledger", "", descLedger)
+	flagAddReport := flag.String("add", "", descAddReport)
+	flagHistory := flag.String("history", "", descHistory)
+	flagTournament := flag.String("tournament", "", descTournament)
+	flagBots := flag.String("bots", "", descBots)
+	flagGames := flag.String("games", "", descGames)
+	flagEnv := flag.String("env", "", descEnv)
+	flagSimAI := flag.String("simai", "", descSimAI)
+	flagDebug := flag.String("debug", "", descDebug)
+	flagRewind := flag.String("rewind", "", descRewind)
+	flagMCTS := flag.String("mcts", "", descMCTS)
+	flagTune := flag.String("tune", "", descTune)
+	flagBudget := flag.String("budget", "", descBudget)
 	flag.Parse()
 
 	// print defaults
@@ -68,7 +112,7 @@ func main() {
 	// --- start interactive CLI --- //
 
 	// mode
-	mode := getString(flagMode, descMode, []string{"singleplayer", "server", "client", "simai"}, nil)
+	mode := getString(flagMode, descMode, []string{"singleplayer", "server", "client", "simai", "mcts", "arena", "tune", "rlenv", "rating", "tournament"}, nil)
 	switch mode {
 	case "server":
 		// server
@@ -82,6 +126,8 @@ func main() {
 		neutralAmount := getInt(flagNeutralAmount, descNeutralAmount, nil, []string{""})
 		neutralMaxSpeed := getInt(flagNeutralMaxSpeed, descNeutralMaxSpeed, nil, []string{""})
 		neutralMaxVapor := getInt(flagNeutralMaxVapor, descNeutralMaxVapor, nil, []string{""})
+		rules := getRules(flagRules, descRules)
+		lag := getOptionalInt(flagLag, descLag)
 		headless := getBool(flagHeadless, descHeadless, nil, []string{""})
 		// local player
 		var localPlayer bool
@@ -105,13 +151,73 @@ func main() {
 		if remotePlayer || headless {
 			remoteAmount = getInt(flagRemoteAmount, descRemoteAmount, nil, []string{""})
 		}
+		// statistics
+		var report string
+		var ledger string
+		seed := time.Now().UnixMicro()
+		var timeout int
+		var lockstep int
+		if headless {
+			report = getString(flagReport, descReport, nil, nil)
+			ledger = *flagLedger // optional: rate the game
+			if s := getOptionalInt(flagSeed, descSeed); s != 0 {
+				seed = int64(s)
+			}
+			timeout = getOptionalInt(flagTimeout, descTimeout)
+			lockstep = getOptionalInt(flagLockstep, descLockstep)
+		}
 
 		// START SERVER
 		if !headless {
-			gui.ModeServerGUI(host, port, screenWidth, screenHeight, gameSpeed, float32(playerVapor), neutralAmount, float32(neutralMaxSpeed), float32(neutralMaxVapor), remotePlayer, remoteAmount, localPlayer, localName, localColor)
+			rewind := *flagRewind != "" && getBool(flagRewind, descRewind, nil, nil)
+			gui.ModeServerGUI(host, port, screenWidth, screenHeight, gameSpeed, float32(playerVapor), neutralAmount, float32(neutralMaxSpeed), float32(neutralMaxVapor), rules, lag, remotePlayer, remoteAmount, localPlayer, localName, localColor, rewind)
 		} else {
 			// create world
-			sWorld := core.NewWorld(screenWidth, screenHeight, gameSpeed, neutralAmount, float32(neutralMaxSpeed), float32(neutralMaxVapor), time.Now().UnixMicro())
+			sWorld := core.NewWorld(screenWidth, screenHeight, gameSpeed, neutralAmount, float32(neutralMaxSpeed), float32(neutralMaxVapor), seed)
+			sWorld.SetRules(rules)
+			sWorld.EnableHistory(lag * gameSpeed / 1000)
+			sWorld.Freeze(true) // no updates (and no game end) until all players have joined (see remote.RunServer)
+			// match report and rating (at game end or timeout)
+			var col *stats.Collector
+			if report != "" || ledger != "" {
+				col = stats.NewCollector(sWorld)
+			}
+			end := make(chan struct{}, 1)
+			if timeout > 0 {
+				sWorld.AddListener(func(e core.Event) {
+					if e.Kind == core.EventTick && e.Iteration >= uint64(timeout*gameSpeed) {
+						select {
+						case end <- struct{}{}:
+						default:
+						}
+					}
+				})
+			}
+			go func() {
+				if col != nil {
+					select {
+					case <-col.Done():
+					case <-end:
+					}
+				} else {
+					<-end
+				}
+				if report != "" {
+					if err := col.Report().Write(report); err != nil {
+						log.Fatalf("err: report: %v", err)
+					}
+					fmt.Printf("REPORT %s.json %s.md\n", report, report)
+				}
+				if ledger != "" {
+					addRating(ledger, col.Report())
+				}
+				os.Exit(0)
+			}()
+			// lock-step: the server updates the world
+			if lockstep > 0 {
+				remote.RunLockstepServer(host, port, float32(playerVapor), sWorld, remoteAmount, nil, time.Duration(lockstep)*time.Millisecond)
+				return
+			}
 			// extern update loop
 			go func() {
 				for range time.Tick(1000 / time.Duration(gameSpeed) * time.Millisecond) {
@@ -119,7 +225,7 @@ func main() {
 				}
 			}()
 			// run server
-			remote.RunServer(host, port, float32(playerVapor), sWorld, remoteAmount)
+			remote.RunServer(host, port, float32(playerVapor), sWorld, remoteAmount, nil)
 		}
 
 	case "client":
@@ -145,12 +251,159 @@ func main() {
 		// SimAI
 		localColor := getString(flagLocalColor, descLocalColor, []string{"blue", "gray", "orange", "purple", "red"}, nil)
 		localName := fmt.Sprintf("SimAI-%s", localColor)
+		config := getSimAIConfigs(flagSimAI)[0]
+		annotate := *flagDebug != "" && getBool(flagDebug, descDebug, nil, nil)
 
 		// START SimAI (client)
-		simai.RunSimAI(host, port, localName, localColor)
+		simai.RunSimAI(host, port, localName, localColor, config, annotate)
+
+	case "mcts":
+		// server
+		host := getString(flagHost, descHost, nil, []string{""})
+		port := getString(flagPort, descPort, nil, []string{""})
+		// MCTS
+		localColor := getString(flagLocalColor, descLocalColor, []string{"blue", "gray", "orange", "purple", "red"}, nil)
+		localName := fmt.Sprintf("MCTS-%s", localColor)
+		budget := time.Duration(getOptionalInt(flagBudget, descBudget)) * time.Millisecond
+
+		// START MCTS (client)
+		mcts.RunMCTS(host, port, localName, localColor, budget)
+
+	case "arena":
+		rules := getRules(flagRules, descRules)
+		bots := getInt(flagBots, descBots, []string{"2", "3", "4", "5"}, nil)
+		mctsBots := getOptionalInt(flagMCTS, descMCTS)
+		budget := time.Duration(getOptionalInt(flagBudget, descBudget)) * time.Millisecond
+		games := getInt(flagGames, descGames, nil, []string{""})
+		seed := time.Now().UnixMicro()
+		if s := getOptionalInt(flagSeed, descSeed); s != 0 {
+			seed = int64(s) // first game, then +1 per game
+		}
+		report := *flagReport // optional: path prefix of the match reports
+		ledger := *flagLedger // optional: rate the games
+		configs := getSimAIConfigs(flagSimAI)
+
+		// START ARENA (in-process bots)
+		colors := []string{"blue", "gray", "orange", "purple", "red"}
+		for g := 1; g <= games; g++ {
+			start := time.Now()
+			world := core.NewWorld(2048, 1152, 60, 100, 7, 200, seed+int64(g-1))
+			world.SetRules(rules)
+			arena := bot.NewArena(world, 10)
+			for i, color := range colors[:bots] {
+				if i < mctsBots {
+					m := mcts.NewMCTS(fmt.Sprintf("MCTS-%s", color), color)
+					if budget > 0 {
+						m.Budget = budget
+					}
+					arena.Add(m, 600)
+					continue
+				}
+				ai, err := simai.NewSimAIConfig(fmt.Sprintf("SimAI-%s", color), color, configs[i%len(configs)])
+				if err != nil {
+					log.Fatalf("err: arena: %v", err)
+				}
+				ai.Budget = budget
+				arena.Add(ai, 600)
+			}
+			r := arena.Run()
+			fmt.Printf("GAME %d: winner '%s' after %d iterations (%.1f s game time in %v)\n", g, r.Winner, r.Iterations, r.Seconds, time.Since(start).Round(time.Millisecond))
+
+			if report != "" {
+				path := fmt.Sprintf("%s-%03d", report, g)
+				if err := r.Write(path); err != nil {
+					log.Fatalf("err: report: %v", err)
+				}
+			}
+			if ledger != "" {
+				addRating(ledger, r)
+			}
+		}
+
+	case "tune":
+		var c simai.TuneConfig
+		if *flagTune != "" {
+			var err error
+			if c, err = simai.LoadTuneConfig(*flagTune); err != nil {
+				log.Fatalf("err: tune: %v", err)
+			}
+		}
+
+		// START EVOLUTION (in-process SimAI bots)
+		pop, err := simai.Tune(c)
+		if err != nil {
+			log.Fatalf("err: tune: %v", err)
+		}
+		fmt.Printf("BEST: %.3f %+v\n", pop[0].Fitness, pop[0].Weights)
+
+	case "rlenv":
+		var c rlenv.Config
+		if *flagEnv != "" {
+			var err error
+			if c, err = rlenv.LoadConfig(*flagEnv); err != nil {
+				log.Fatalf("err: rlenv: %v", err)
+			}
+		}
+
+		// START ENVIRONMENT (tcp or stdio)
+		var err error
+		if *flagPort != "" {
+			err = rlenv.ServeTcp(*flagHost, *flagPort, c)
+		} else {
+			err = rlenv.Serve(c, os.Stdin, os.Stdout)
+		}
+		if err != nil {
+			log.Fatalf("err: rlenv: %v", err)
+		}
+
+	case "rating":
+		ledger := *flagLedger
+		if ledger == "" {
+			ledger = "ratings.json"
+		}
+
+		// add game
+		if *flagAddReport != "" {
+			r, err := rating.LoadReport(*flagAddReport)
+			if err != nil {
+				log.Fatalf("err: rating: %v", err)
+			}
+			addRating(ledger, r)
+		}
+
+		// print leaderboard or history
+		l, err := rating.Load(ledger)
+		if err != nil {
+			log.Fatalf("err: rating: %v", err)
+		}
+		if *flagHistory != "" {
+			h, err := l.HistoryTable(*flagHistory)
+			if err != nil {
+				log.Fatalf("err: rating: %v", err)
+			}
+			fmt.Print(h)
+		} else {
+			fmt.Print(l.Table())
+		}
+
+	case "tournament":
+		path := getString(flagTournament, descTournament, nil, []string{""})
+		c, err := tournament.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("err: tournament: %v", err)
+		}
+
+		// START TOURNAMENT
+		t, err := tournament.Run(c, *flagLedger)
+		if err != nil {
+			log.Fatalf("err: tournament: %v", err)
+		}
+		fmt.Print(t.Table())
 
 	case "singleplayer":
-		gui.ModeServerGUI("", "", 2048, 1152, 60, 600, 100, 7, 200, false, 0, true, "Cloudy", "blue")
+		rules := getRules(flagRules, descRules)
+		rewind := *flagRewind != "" && getBool(flagRewind, descRewind, nil, nil)
+		gui.ModeServerGUI("", "", 2048, 1152, 60, 600, 100, 7, 200, rules, 0, false, 0, true, "Cloudy", "blue", rewind)
 
 	default:
 		flag.PrintDefaults()
@@ -210,6 +463,57 @@ func getInt(flag *string, description string, whitelist, blacklist []string) int
 	return int(i)
 }
 
+func getOptionalInt(flag *string, description string) int {
+	in := getString(flag, description, nil, nil)
+	if in == "" {
+		return 0 // not set
+	}
+	i, err := strconv.ParseInt(in, 10, 32)
+	if err != nil {
+		fmt.Printf("err: getOptionalInt: can't parse int: %s\n", err)
+	}
+	return int(i)
+}
+
+func addRating(path string, r *stats.Report) {
+	var g *rating.Game
+	err := rating.Update(path, func(l *rating.Ledger) (err error) {
+		g, err = l.AddGame(rating.Results(r))
+		return err
+	})
+	if err != nil {
+		log.Fatalf("err: addRating: %v", err)
+	}
+	fmt.Printf("RATING game %d added to %s\n", g.ID, path)
+}
+
+func getRules(flag *string, description string) core.Rules {
+	in := getString(flag, description, nil, nil)
+	if in == "" {
+		return core.Rules{} // classic game
+	}
+	r, err := core.LoadRules(in)
+	if err != nil {
+		log.Fatalf("err: getRules: %v", err)
+	}
+	return r
+}
+
+func getSimAIConfigs(flag *string) []simai.Config {
+	if *flag == "" {
+		return []simai.Config{{}} // classic SimAI
+	}
+	var ret []simai.Config
+	for _, path := range strings.Split(*flag, ",") {
+		c, err := simai.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("err: getSimAIConfigs: %v", err)
+		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
 func checkLists(in string, whitelist, blacklist []string) (err string) {
 	// block invalid input
 	if blacklist != nil {
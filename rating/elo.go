@@ -0,0 +1,44 @@
+package rating
+
+import (
+	"math"
+)
+
+const (
+	InitialRating = 1500.0 // rating of a new player
+	KFactor       = 32.0   // max rating change of a two player game
+)
+
+// Result is the placement of a player in a game.
+type Result struct {
+	Name string
+	Rank int // 1 is the best; players with the same rank are tied
+}
+
+// elo calculates the rating changes of a multiplayer game.
+// Each player plays a two player Elo game against every other player.
+// The changes are divided by the number of opponents, so that the sum of all changes is zero.
+func elo(ratings []float64, ranks []int) []float64 {
+	n := len(ratings)
+	delta := make([]float64, n)
+	if n < 2 {
+		return delta
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (ratings[j]-ratings[i])/400))
+			score := 0.5 // tie
+			if ranks[i] < ranks[j] {
+				score = 1
+			} else if ranks[i] > ranks[j] {
+				score = 0
+			}
+			delta[i] += KFactor / float64(n-1) * (score - expected)
+		}
+	}
+	return delta
+}
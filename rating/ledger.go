@@ -0,0 +1,265 @@
+package rating
+
+import (
+	"CloudWars/stats"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ledger is a persistent list of players and games (json file).
+type Ledger struct {
+	path    string
+	Players map[string]*Player
+	Games   []*Game
+}
+
+// Player is the rating of one player.
+type Player struct {
+	Name    string
+	Rating  float64
+	Games   int
+	Wins    int
+	History []Entry // rating after each game
+}
+
+// Entry is the rating of a player after a game.
+type Entry struct {
+	Game   int // Game.ID
+	Rank   int
+	Rating float64
+}
+
+// Game is a rated game.
+type Game struct {
+	ID      int
+	Time    time.Time
+	Results []Result
+}
+
+// Load reads a ledger file. A missing file is an empty ledger.
+func Load(path string) (*Ledger, error) {
+	l := &Ledger{path: path, Players: make(map[string]*Player)}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil // new ledger
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, l); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if l.Players == nil {
+		l.Players = make(map[string]*Player)
+	}
+	return l, nil
+}
+
+// Save writes the ledger file. The file is replaced atomically (temp file and rename),
+// so a crash never leaves a truncated ledger.
+func (l *Ledger) Save() error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // after a failure
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), l.path)
+}
+
+// lock timings of Update
+var (
+	lockRetry   = 50 * time.Millisecond
+	lockTimeout = 30 * time.Second
+	lockStale   = 2 * time.Minute // lock file of a crashed process
+)
+
+// Update loads the ledger, changes it with fn and saves it while holding a lock file (path + ".lock"),
+// so concurrent servers and tournaments that share a ledger don't lose games.
+// Nothing is saved if fn fails.
+func Update(path string, fn func(l *Ledger) error) error {
+	unlock, err := lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	l, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(l); err != nil {
+		return err
+	}
+	return l.Save()
+}
+
+// lock creates the lock file exclusively (waits for other processes) and returns the unlock function.
+func lock(path string) (unlock func(), err error) {
+	start := time.Now()
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		} else if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		// locked by another process
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStale {
+			_ = os.Remove(path) // stale
+			continue
+		}
+		if time.Since(start) > lockTimeout {
+			return nil, fmt.Errorf("%s: locked", path)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// AddGame rates a finished game and adds it to the ledger (see Save).
+func (l *Ledger) AddGame(results []Result) (*Game, error) {
+	if len(results) < 2 {
+		return nil, errors.New("a game needs at least two players")
+	}
+
+	// current ratings
+	ratings := make([]float64, len(results))
+	ranks := make([]int, len(results))
+	for i, r := range results {
+		p := l.Players[r.Name]
+		if p == nil {
+			p = &Player{Name: r.Name, Rating: InitialRating}
+			l.Players[r.Name] = p
+		}
+		ratings[i] = p.Rating
+		ranks[i] = r.Rank
+	}
+
+	// new game
+	g := &Game{ID: len(l.Games) + 1, Time: time.Now().UTC(), Results: results}
+	l.Games = append(l.Games, g)
+
+	// update ratings
+	for i, d := range elo(ratings, ranks) {
+		p := l.Players[results[i].Name]
+		p.Rating += d
+		p.Games++
+		if results[i].Rank == 1 {
+			p.Wins++
+		}
+		p.History = append(p.History, Entry{Game: g.ID, Rank: results[i].Rank, Rating: p.Rating})
+	}
+
+	return g, nil
+}
+
+// Leaderboard returns all players sorted by rating.
+func (l *Ledger) Leaderboard() []*Player {
+	list := make([]*Player, 0, len(l.Players))
+	for _, p := range l.Players {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Rating != list[j].Rating {
+			return list[i].Rating > list[j].Rating
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Table returns the leaderboard as text table.
+func (l *Ledger) Table() string {
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "%4s  %-25s %7s %6s %6s\n", "#", "player", "rating", "games", "wins")
+	for i, p := range l.Leaderboard() {
+		fmt.Fprintf(sb, "%4d  %-25s %7.0f %6d %6d\n", i+1, p.Name, p.Rating, p.Games, p.Wins)
+	}
+	return sb.String()
+}
+
+// HistoryTable returns the rating history of a player as text table.
+func (l *Ledger) HistoryTable(name string) (string, error) {
+	p := l.Players[name]
+	if p == nil {
+		return "", fmt.Errorf("unknown player '%s'", name)
+	}
+
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "%s: %.0f (%d games, %d wins)\n", p.Name, p.Rating, p.Games, p.Wins)
+	fmt.Fprintf(sb, "%6s  %-20s %4s %7s\n", "game", "time", "rank", "rating")
+	for _, e := range p.History {
+		var t string
+		if e.Game >= 1 && e.Game <= len(l.Games) {
+			t = l.Games[e.Game-1].Time.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(sb, "%6d  %-20s %4d %7.0f\n", e.Game, t, e.Rank, e.Rating)
+	}
+	return sb.String(), nil
+}
+
+// Results returns the placements of a match report.
+// The winner is first, then all players by final vapor. Dead players are tied.
+func Results(r *stats.Report) []Result {
+	players := make([]*stats.Player, len(r.Players))
+	copy(players, r.Players)
+	score := func(p *stats.Player) float32 {
+		if p.Name == r.Winner {
+			return float32(1e30)
+		}
+		return p.FinalVapor
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		return score(players[i]) > score(players[j])
+	})
+
+	results := make([]Result, 0, len(players))
+	for i, p := range players {
+		rank := i + 1
+		if i > 0 && score(p) == score(players[i-1]) {
+			rank = results[i-1].Rank // tie
+		}
+		results = append(results, Result{Name: p.Name, Rank: rank})
+	}
+	return results
+}
+
+// LoadReport reads a match report (json) of the server (see stats.Report).
+func LoadReport(path string) (*stats.Report, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := new(stats.Report)
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,133 @@
+package rating
+
+import (
+	"CloudWars/stats"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestElo(t *testing.T) {
+	// two players: the classic Elo
+	d := elo([]float64{1500, 1500}, []int{1, 2})
+	if d[0] != 16 || d[1] != -16 {
+		t.Errorf("fail: %v", d)
+	}
+
+	// the favourite wins less
+	d = elo([]float64{1700, 1500}, []int{1, 2})
+	if d[0] <= 0 || d[0] >= 16 || math.Abs(d[0]+d[1]) > 1e-9 {
+		t.Errorf("fail: %v", d)
+	}
+
+	// multiplayer: zero sum, tie
+	d = elo([]float64{1500, 1600, 1400, 1500}, []int{1, 2, 3, 3})
+	var sum float64
+	for _, v := range d {
+		sum += v
+	}
+	if math.Abs(sum) > 1e-9 || d[0] <= d[1] || d[2] >= 0 {
+		t.Errorf("fail: %v", d)
+	}
+}
+
+func TestResults(t *testing.T) {
+	r := &stats.Report{Winner: "B", Players: []*stats.Player{
+		{Name: "A", FinalVapor: 300},
+		{Name: "B", FinalVapor: 200}, // winner (timeout with less vapor is not possible, but the winner is first)
+		{Name: "C", FinalVapor: 0},
+		{Name: "D", FinalVapor: 0},
+	}}
+	exp := []Result{{"B", 1}, {"A", 2}, {"C", 3}, {"D", 3}}
+	if got := Results(r); !reflect.DeepEqual(got, exp) {
+		t.Errorf("fail: %v", got)
+	}
+}
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	l, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.AddGame([]Result{{"A", 1}}); err == nil {
+		t.Errorf("fail: one player")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := l.AddGame([]Result{{"A", 1}, {"B", 2}, {"C", 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// reload
+	l, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := l.Leaderboard()
+	if len(board) != 3 || board[0].Name != "A" || board[2].Name != "C" || board[0].Wins != 3 || board[1].Games != 3 || len(l.Games) != 3 {
+		t.Errorf("fail: %v", l.Table())
+	}
+	if !strings.Contains(l.Table(), "A") {
+		t.Errorf("fail: %v", l.Table())
+	}
+	if h, err := l.HistoryTable("B"); err != nil || strings.Count(h, "\n") != 5 {
+		t.Errorf("fail: %v %v", h, err)
+	}
+	if _, err := l.HistoryTable("X"); err == nil {
+		t.Errorf("fail: unknown player")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ratings.json")
+
+	// concurrent updates don't lose games
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(path, func(l *Ledger) error {
+				_, err := l.AddGame([]Result{{"A", 1}, {"B", 2}})
+				return err
+			})
+			if err != nil {
+				t.Errorf("fail: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	l, err := Load(path)
+	if err != nil || len(l.Games) != 8 || l.Players["A"].Wins != 8 {
+		t.Fatalf("fail: %v %v", err, l)
+	}
+
+	// failed update: not saved; no lock or temp files left
+	if err := Update(path, func(l *Ledger) error {
+		_, err := l.AddGame(nil)
+		return err
+	}); err == nil {
+		t.Errorf("fail: no error")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("fail: %v", files)
+	}
+
+	// stale lock of a crashed process
+	_ = os.WriteFile(path+".lock", nil, 0644)
+	old := time.Now().Add(-lockStale - time.Second)
+	_ = os.Chtimes(path+".lock", old, old)
+	if err := Update(path, func(l *Ledger) error { return nil }); err != nil {
+		t.Errorf("fail: %v", err)
+	}
+}
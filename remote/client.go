@@ -2,7 +2,9 @@ package remote
 
 import (
 	"CloudWars/core"
+	"CloudWars/debug"
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
@@ -68,6 +70,18 @@ func (t *TcpClient) List() string {
 	return comWriteRead(t, "list")
 }
 
+// Events returns all world events since the last call (or since the connection was established).
+func (t *TcpClient) Events() []core.Event {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	var events []core.Event
+	if err := json.Unmarshal([]byte(comWriteRead(t, "evnt")), &events); err != nil {
+		fmt.Printf("Events: %v\n", err)
+	}
+	return events
+}
+
 // Name set the player name
 // Use this before calling Play()
 // Returns the server response (OK or ERR) as a string.
@@ -114,6 +128,19 @@ func (t *TcpClient) Move(v *core.Velocity) string {
 	return comWriteRead(t, com)
 }
 
+// MoveAt sends a move command that is based on the world of the given iteration (lag compensation).
+// Returns the server response (OK with the applied iteration or ERR) as a string.
+func (t *TcpClient) MoveAt(v *core.Velocity, iteration uint64) string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if v == nil {
+		return "err: nil"
+	}
+	com := fmt.Sprintf("move%f;%f;%d", v.X, v.Y, iteration)
+	return comWriteRead(t, com)
+}
+
 // Kill blasts the controlled cloud and removes it from the game.
 // Returns the server response (OK or ERR) as a string.
 func (t *TcpClient) Kill() string {
@@ -123,6 +150,51 @@ func (t *TcpClient) Kill() string {
 	return comWriteRead(t, "kill")
 }
 
+// Step ends the turn in lock-step mode and waits for the next step of the server (see RunLockstepServer).
+// Returns the server response (OK with the new iteration or ERR) as a string.
+func (t *TcpClient) Step() string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return comWriteRead(t, "step")
+}
+
+// Debug publishes an annotation of the player (see debug.Annotation), the GUI draws it in the debug overlay.
+// Returns the server response (OK or ERR) as a string.
+func (t *TcpClient) Debug(a *debug.Annotation) string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	b, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Sprintf("err: %v", err)
+	}
+	return comWriteRead(t, "dbug"+string(b))
+}
+
+// Annotations returns the last annotations of all players.
+func (t *TcpClient) Annotations() []*debug.Annotation {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	var aa []*debug.Annotation
+	if err := json.Unmarshal([]byte(comWriteRead(t, "dbug")), &aa); err != nil {
+		fmt.Printf("Annotations: %v\n", err)
+	}
+	return aa
+}
+
+// Verify compares a locally simulated world with the server (desync detection).
+// The local world is updated until it reaches the iteration of the server (see core.World Verify).
+// Returns the current server world and whether both worlds are equal.
+func (t *TcpClient) Verify(local *core.World) (server *core.World, ok bool) {
+	server = new(core.World)
+	server.FromJson(t.List())
+
+	iteration, _, _, _, _ := server.Stats()
+	return server, local.Verify(iteration, server.Checksum())
+}
+
 //----- Helper -------------------------------------------------------------------------------------------------------//
 
 func comWriteRead(t *TcpClient, com string) string {
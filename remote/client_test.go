@@ -2,6 +2,7 @@ package remote
 
 import (
 	"CloudWars/core"
+	"CloudWars/debug"
 	"strings"
 	"testing"
 	"time"
@@ -12,7 +13,7 @@ func TestNewTcpClient(t *testing.T) {
 	// init
 	world := core.NewWorld(2000, 1000, 60, 30, 20, 400, 1337)
 	world.Update()
-	go RunServer("localhost", "8686", 800, world, 1)
+	go RunServer("localhost", "8686", 800, world, 1, nil)
 	time.Sleep(1 * time.Second)
 	client := NewTcpClient("localhost", "8686")
 
@@ -23,6 +24,12 @@ func TestNewTcpClient(t *testing.T) {
 	if res := client.Move(&core.Velocity{}); res != "err: you're not playing" {
 		t.Errorf("fail: %s", res)
 	}
+	if res := client.Step(); res != "err: lock-step mode is off" {
+		t.Errorf("fail: %s", res)
+	}
+	if res := client.Debug(&debug.Annotation{Text: "hi"}); res != "err: you're not playing" {
+		t.Errorf("fail: %s", res)
+	}
 	if res := client.Name(""); res != "err: invalid name length" {
 		t.Errorf("fail: %s", res)
 	}
@@ -43,6 +50,18 @@ func TestNewTcpClient(t *testing.T) {
 	if res := client.Play(); res != "ok: the game begins when all players are ready" {
 		t.Errorf("fail: %s", res)
 	}
+	if events := client.Events(); len(events) != 1 || events[0].Kind != core.EventSpawn || events[0].Player != "Hanspeter" {
+		t.Errorf("fail: %v", events)
+	}
+	if events := client.Events(); events == nil || len(events) != 0 {
+		t.Errorf("fail: %v", events)
+	}
+	if res := client.Debug(&debug.Annotation{Target: "x", Candidates: []debug.Candidate{{X: 1, Y: 2, Score: 3}}}); res != "ok" {
+		t.Errorf("fail: %s", res)
+	}
+	if aa := client.Annotations(); len(aa) != 1 || aa[0].Player != "Hanspeter" || aa[0].Target != "x" || aa[0].Candidates[0].Score != 3 {
+		t.Errorf("fail: %+v", aa)
+	}
 	if res := client.Move(nil); res != "err: nil" {
 		t.Errorf("fail: %s", res)
 	}
@@ -52,12 +71,42 @@ func TestNewTcpClient(t *testing.T) {
 	if res := client.Move(core.NewVelocityByAngle(45, 33)); res != "ok" {
 		t.Errorf("fail: %s", res)
 	}
+	if _, ok := client.Verify(world.Clone()); !ok {
+		t.Errorf("fail: same world")
+	}
+	ahead := world.Clone()
+	ahead.Update()
+	if server, ok := client.Verify(ahead); ok || server == nil {
+		t.Errorf("fail: the local world is ahead")
+	}
+	if res := client.MoveAt(core.NewVelocityByAngle(45, 10), 1); res != "ok: 1" {
+		t.Errorf("fail: %s", res)
+	}
+	if res := client.MoveAt(core.NewVelocityByAngle(45, 10), 5); res != "err: invalid iteration" {
+		t.Errorf("fail: %s", res)
+	}
+	world.EnableHistory(5)
+	for i := 0; i < 10; i++ {
+		world.Update()
+	}
+	if res := client.MoveAt(core.NewVelocityByAngle(45, 10), 8); res != "ok: 8" {
+		t.Errorf("fail: %s", res)
+	}
+	if res := client.MoveAt(core.NewVelocityByAngle(45, 10), 2); res != "err: stale move" {
+		t.Errorf("fail: %s", res)
+	}
 	if res := client.Kill(); res != "ok" {
 		t.Errorf("fail: %s", res)
 	}
 	if res := client.Kill(); res != "err: you're already dead" {
 		t.Errorf("fail: %s", res)
 	}
+	if aa := client.Annotations(); len(aa) != 0 {
+		t.Errorf("fail: annotation of a dead player: %+v", aa)
+	}
+	if res := client.Debug(&debug.Annotation{Text: "hi"}); res != "err: you're not playing" {
+		t.Errorf("fail: %s", res)
+	}
 	if res := client.Close(); res != "ok" {
 		t.Errorf("fail: %s", res)
 	}
@@ -0,0 +1,110 @@
+package remote
+
+import (
+	"CloudWars/core"
+	"sync"
+	"time"
+)
+
+// lockstep advances the world step by step (see RunLockstepServer).
+// A step ends when all living players have sent an action (move, kill or step) or after the timeout.
+type lockstep struct {
+	world   *core.World
+	steps   int           // iterations per step
+	timeout time.Duration // max wait for the players
+
+	mux       sync.Mutex
+	players   map[string]bool // player -> action sent in this step
+	next      chan struct{}   // closed at the end of the step
+	submitted chan struct{}   // all players have sent an action
+}
+
+func newLockstep(world *core.World, timeout time.Duration) *lockstep {
+	steps := world.GameSpeed() / 10 // poll limit: 10 requests per second
+	if steps < 1 {
+		steps = 1
+	}
+	return &lockstep{
+		world:     world,
+		steps:     steps,
+		timeout:   timeout,
+		players:   make(map[string]bool),
+		next:      make(chan struct{}),
+		submitted: make(chan struct{}, 1),
+	}
+}
+
+// run is the update loop
+func (l *lockstep) run() {
+	timer := time.NewTimer(l.timeout)
+	for {
+		// wait for the players
+		select {
+		case <-l.submitted:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+		}
+
+		// next step
+		for i := 0; i < l.steps; i++ {
+			l.world.Update()
+		}
+
+		l.mux.Lock()
+		for name := range l.players {
+			l.players[name] = false
+		}
+		select {
+		case <-l.submitted: // outdated
+		default:
+		}
+		close(l.next)
+		l.next = make(chan struct{})
+		l.mux.Unlock()
+
+		timer.Reset(l.timeout)
+	}
+}
+
+// join adds a player.
+func (l *lockstep) join(name string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.players[name] = false
+}
+
+// leave removes a player (disconnected).
+func (l *lockstep) leave(name string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	delete(l.players, name)
+	l.check()
+}
+
+// submit marks the action of a player and returns a channel that is closed at the end of the step.
+func (l *lockstep) submit(name string) <-chan struct{} {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if _, ok := l.players[name]; ok {
+		l.players[name] = true
+	}
+	l.check()
+	return l.next
+}
+
+// check ends the step when all living players have sent an action (not thread-safe).
+func (l *lockstep) check() {
+	for name, ok := range l.players {
+		if !ok {
+			if l.world.Alive(name) {
+				return // wait
+			}
+		}
+	}
+	select {
+	case l.submitted <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"CloudWars/core"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRunLockstepServer(t *testing.T) {
+	world := core.NewWorld(2000, 1000, 60, 30, 20, 400, 1337)
+	go RunLockstepServer("localhost", "8688", 800, world, 2, nil, 500*time.Millisecond)
+	time.Sleep(1 * time.Second)
+
+	a := NewTcpClient("localhost", "8688")
+	a.Name("A")
+	b := NewTcpClient("localhost", "8688")
+	b.Name("B")
+	if res := a.Play(); res != "ok: the game begins when all players are ready" {
+		t.Errorf("fail: %s", res)
+	}
+	if res := b.Play(); res != "ok: the game begins when all players are ready" {
+		t.Errorf("fail: %s", res)
+	}
+	a.Step() // sync with the steps
+
+	// the step ends with the action of the last player
+	step := make(chan string)
+	go func() { step <- a.Step() }()
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	if res := b.Move(core.NewVelocityByAngle(45, 33)); res != "ok" {
+		t.Errorf("fail: %s", res)
+	}
+	res := <-step
+	if time.Since(start) > 300*time.Millisecond {
+		t.Errorf("fail: step after %v", time.Since(start))
+	}
+	var iteration uint64
+	if _, err := fmt.Sscanf(res, "ok: %d", &iteration); err != nil || iteration == 0 {
+		t.Errorf("fail: %s", res)
+	}
+
+	// timeout: B does nothing
+	start = time.Now()
+	if res := a.Step(); res != fmt.Sprintf("ok: %d", iteration+6) || time.Since(start) < 400*time.Millisecond {
+		t.Errorf("fail: %s after %v", res, time.Since(start))
+	}
+
+	// disconnected players are not waited for
+	b.Close()
+	time.Sleep(100 * time.Millisecond)
+	start = time.Now()
+	a.Step()
+	if time.Since(start) > 300*time.Millisecond {
+		t.Errorf("fail: step after %v", time.Since(start))
+	}
+	a.Close()
+}
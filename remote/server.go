@@ -2,7 +2,9 @@ package remote
 
 import (
 	"CloudWars/core"
+	"CloudWars/debug"
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -11,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type server struct {
@@ -22,13 +25,30 @@ type server struct {
 	waitPlayer     int
 	players        []string
 	mux            *sync.Mutex
+	lock           *lockstep    // nil: real time (external update loop)
+	board          *debug.Board // annotations of the bots (see command 'dbug')
 }
 
 // RunServer starts a server and makes the game world available remotely.
 // The server controls remote player clouds the game via the world reference.
 // The initPlayerSize attribute determines how much vapor remotely generated player clouds will have.
 // The waitPlayer attribute controls how many players will be waited for.
-func RunServer(host, port string, initPlayerSize float32, world *core.World, waitPlayer int) {
+// The board keeps the annotations of the bots until they die or disconnect (see command 'dbug', nil: own board).
+// Move commands with an iteration are lag compensated within the history of the world (see core.World EnableHistory).
+func RunServer(host, port string, initPlayerSize float32, world *core.World, waitPlayer int, board *debug.Board) {
+	runServer(host, port, initPlayerSize, world, waitPlayer, board, nil)
+}
+
+// RunLockstepServer starts a server that updates the world itself in lock-step (no external update loop).
+// The world advances 1/10 second (game time) when all living players have sent an action (move, kill or step)
+// or after the timeout, so the game runs as fast as the players can think.
+func RunLockstepServer(host, port string, initPlayerSize float32, world *core.World, waitPlayer int, board *debug.Board, timeout time.Duration) {
+	lock := newLockstep(world, timeout)
+	go lock.run()
+	runServer(host, port, initPlayerSize, world, waitPlayer, board, lock)
+}
+
+func runServer(host, port string, initPlayerSize float32, world *core.World, waitPlayer int, board *debug.Board, lock *lockstep) {
 
 	// Listen for incoming connections.
 	l, err := net.Listen("tcp", host+":"+port)
@@ -44,6 +64,16 @@ func RunServer(host, port string, initPlayerSize float32, world *core.World, wai
 	// Freeze world
 	world.Freeze(true) // undo in registerPlayer()
 
+	// annotations of the living players only
+	if board == nil {
+		board = debug.NewBoard()
+	}
+	world.AddListener(func(e core.Event) {
+		if e.Kind == core.EventDeath {
+			board.Remove(e.Player)
+		}
+	})
+
 	// server
 	ser := &server{
 		host:           host,
@@ -53,6 +83,8 @@ func RunServer(host, port string, initPlayerSize float32, world *core.World, wai
 		waitPlayer:     waitPlayer,
 		players:        make([]string, 0, waitPlayer),
 		mux:            new(sync.Mutex),
+		lock:           lock,
+		board:          board,
 	}
 
 	fmt.Println("START SERVER [" + host + ":" + port + "]")
@@ -84,6 +116,20 @@ func handleRequest(conn net.Conn, ser *server) {
 	var color = "red"
 	var me *core.Cloud
 
+	// collect world events (see command 'evnt')
+	events := new(eventQueue)
+	defer ser.world.AddListener(events.add)()
+
+	// lock-step: do not wait for disconnected players, and drop their annotations
+	defer func() {
+		if ser.lock != nil && me != nil {
+			ser.lock.leave(name)
+		}
+		if me != nil {
+			ser.board.Remove(name)
+		}
+	}()
+
 	// loop
 	for {
 		// read one line (ended with \n or \r\n)
@@ -105,10 +151,19 @@ func handleRequest(conn net.Conn, ser *server) {
 				break // exit loop and close connection
 			}
 
+		} else if com == "evnt" { //------------------------------------------------------------------------------< EVNT
+			b, _ := json.Marshal(events.take())
+			if comWrite(conn, string(b)) {
+				break // exit loop and close connection
+			}
+
 		} else if com == "play" { //------------------------------------------------------------------------------< PLAY
 			if me == nil {
 				if e := ser.registerPlayer(name); e == nil {
 					me = ser.world.AddPlayer(name, color, nil, ser.initPlayerSize)
+					if ser.lock != nil {
+						ser.lock.join(name)
+					}
 					if comWrite(conn, "ok: the game begins when all players are ready") {
 						break // exit loop and close connection
 					}
@@ -135,6 +190,7 @@ func handleRequest(conn net.Conn, ser *server) {
 					}
 				} else {
 					ser.world.Kill(me)
+					ser.submit(name)
 					if comWrite(conn, "ok") {
 						break // exit loop and close connection
 					}
@@ -182,8 +238,27 @@ func handleRequest(conn net.Conn, ser *server) {
 				} else {
 					// split input
 					a := strings.Split(line[4:], ";")
-					if len(a) != 2 {
-						if comWrite(conn, "err: invalid input: use 'float32;float32'") {
+					if len(a) == 3 {
+						// lag compensation: move based on an older iteration
+						x, _ := strconv.ParseFloat(a[0], 32)
+						y, _ := strconv.ParseFloat(a[1], 32)
+						i, err := strconv.ParseUint(strings.TrimSpace(a[2]), 10, 64)
+						if err != nil {
+							if comWrite(conn, "err: invalid iteration") {
+								break // exit loop and close connection
+							}
+						} else if applied, err := ser.world.MoveAt(me, core.NewVelocity(float32(x), float32(y)), i); err != nil {
+							if comWrite(conn, fmt.Sprintf("err: %v", err)) {
+								break // exit loop and close connection
+							}
+						} else {
+							ser.submit(name)
+							if comWrite(conn, fmt.Sprintf("ok: %d", applied)) {
+								break // exit loop and close connection
+							}
+						}
+					} else if len(a) != 2 {
+						if comWrite(conn, "err: invalid input: use 'float32;float32' or 'float32;float32;iteration'") {
 							break // exit loop and close connection
 						}
 					} else {
@@ -197,6 +272,7 @@ func handleRequest(conn net.Conn, ser *server) {
 								break // exit loop and close connection
 							}
 						} else {
+							ser.submit(name)
 							if comWrite(conn, "ok") {
 								break // exit loop and close connection
 							}
@@ -205,6 +281,52 @@ func handleRequest(conn net.Conn, ser *server) {
 				}
 			}
 
+		} else if com == "step" { //------------------------------------------------------------------------------< STEP
+			if ser.lock == nil {
+				if comWrite(conn, "err: lock-step mode is off") {
+					break // exit loop and close connection
+				}
+			} else if me == nil {
+				if comWrite(conn, "err: you're not playing") {
+					break // exit loop and close connection
+				}
+			} else {
+				<-ser.lock.submit(name) // wait for the next step
+				iteration, _, _, _, _ := ser.world.Stats()
+				if comWrite(conn, fmt.Sprintf("ok: %d", iteration)) {
+					break // exit loop and close connection
+				}
+			}
+
+		} else if com == "dbug" { //------------------------------------------------------------------------------< DBUG
+			var payload = strings.TrimSpace(line[4:])
+			if payload == "" {
+				// all annotations (e.g. for an observer)
+				b, _ := json.Marshal(ser.board.All())
+				if comWrite(conn, string(b)) {
+					break // exit loop and close connection
+				}
+			} else if me == nil || !ser.world.Alive(name) {
+				if comWrite(conn, "err: you're not playing") {
+					break // exit loop and close connection
+				}
+			} else {
+				// publish the annotation of this player
+				a := new(debug.Annotation)
+				if err := json.Unmarshal([]byte(payload), a); err != nil {
+					if comWrite(conn, fmt.Sprintf("err: invalid annotation: %v", err)) {
+						break // exit loop and close connection
+					}
+				} else {
+					a.Player = name
+					a.Iteration, _, _, _, _ = ser.world.Stats()
+					ser.board.Publish(a)
+					if comWrite(conn, "ok") {
+						break // exit loop and close connection
+					}
+				}
+			}
+
 		} else { // ---- default: invalid command -------------------------------------------------------------< DEFAULT
 			if comWrite(conn, "err: invalid command") {
 				break // exit loop and close connection
@@ -254,3 +376,45 @@ func (ser *server) registerPlayer(name string) error {
 func (ser *server) ready() bool {
 	return len(ser.players) >= ser.waitPlayer
 }
+
+// submit marks the action of a player (lock-step mode only)
+func (ser *server) submit(name string) {
+	if ser.lock != nil {
+		ser.lock.submit(name)
+	}
+}
+
+// maxEvents is the size of the event queue of a connection (the oldest events are discarded).
+const maxEvents = 10000
+
+// eventQueue collects the world events of a connection until the next 'evnt' command.
+type eventQueue struct {
+	mux    sync.Mutex
+	events []core.Event
+}
+
+func (q *eventQueue) add(e core.Event) {
+	if e.Kind == core.EventTick {
+		return // too many
+	}
+
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if len(q.events) >= maxEvents {
+		q.events = q.events[1:]
+	}
+	q.events = append(q.events, e)
+}
+
+func (q *eventQueue) take() []core.Event {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	ret := q.events
+	if ret == nil {
+		ret = make([]core.Event, 0) // json: [] instead of null
+	}
+	q.events = nil
+	return ret
+}
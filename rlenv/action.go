@@ -0,0 +1,47 @@
+package rlenv
+
+import (
+	"CloudWars/core"
+)
+
+const (
+	ActionDiscrete   = "discrete"   // 0: no move, 1..n: direction & strength (see Config Angles & Strengths)
+	ActionContinuous = "continuous" // wind vector x, y (strength < 1: no move)
+)
+
+// Action is the decision of the agent.
+type Action struct {
+	Index int     // discrete
+	X, Y  float32 // continuous
+}
+
+// Space describes an observation or action space (like gym.spaces).
+type Space struct {
+	Type  string  // 'discrete' (N values) or 'box' (Shape, -1: variable length)
+	N     int     `json:",omitempty"`
+	Shape []int   `json:",omitempty"`
+	Low   float32 `json:",omitempty"`
+	High  float32 `json:",omitempty"`
+}
+
+// ActionSpace returns the action space of the environment.
+func (e *Env) ActionSpace() Space {
+	c := e.config
+	if c.Action == ActionContinuous {
+		return Space{Type: "box", Shape: []int{2}, Low: -c.PlayerVapor / 2, High: c.PlayerVapor / 2}
+	}
+	return Space{Type: "discrete", N: 1 + c.Angles*len(c.Strengths)}
+}
+
+// wind returns the move command of an action (nil: no move)
+func (a Action) wind(c Config) *core.Velocity {
+	if c.Action == ActionContinuous {
+		return core.NewVelocity(a.X, a.Y)
+	}
+	if a.Index <= 0 || a.Index > c.Angles*len(c.Strengths) {
+		return nil
+	}
+	i := a.Index - 1
+	angle := float32(i%c.Angles) * 360 / float32(c.Angles)
+	return core.NewVelocityByAngle(angle, c.Strengths[i/c.Angles])
+}
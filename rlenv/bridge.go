@@ -0,0 +1,135 @@
+package rlenv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// request is one line of the bridge protocol.
+type request struct {
+	Cmd    string          // 'spaces', 'reset', 'step' or 'close'
+	Seed   int64           // reset (0: random)
+	Action json.RawMessage // step: index (discrete) or [x, y] (continuous)
+}
+
+// response is the answer to a request (one line).
+type response struct {
+	ObservationSpace *Space    `json:",omitempty"`
+	ActionSpace      *Space    `json:",omitempty"`
+	Observation      []float32 `json:",omitempty"`
+	Reward           float64   `json:",omitempty"`
+	Done             bool      `json:",omitempty"`
+	Info             *Info     `json:",omitempty"`
+	Error            string    `json:",omitempty"`
+}
+
+// LoadConfig reads an environment config (json file).
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Serve runs an environment with the line based json protocol (e.g. stdin & stdout).
+// Each request is answered with one line, 'close' or the end of the input stops the environment.
+func Serve(c Config, r io.Reader, w io.Writer) error {
+	e, err := New(c)
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	out := json.NewEncoder(w)
+	for in.Scan() {
+		var req request
+		var res response
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			res.Error = err.Error()
+		} else {
+			switch req.Cmd {
+			case "spaces":
+				o, a := e.ObservationSpace(), e.ActionSpace()
+				res.ObservationSpace, res.ActionSpace = &o, &a
+			case "reset":
+				seed := req.Seed
+				if seed == 0 {
+					seed = time.Now().UnixNano()
+				}
+				res.Observation = e.Reset(seed)
+				info := e.info()
+				res.Info = &info
+			case "step":
+				a, err := e.action(req.Action)
+				if err != nil {
+					res.Error = err.Error()
+					break
+				}
+				var info Info
+				res.Observation, res.Reward, res.Done, info = e.Step(a)
+				res.Info = &info
+			case "close":
+				return out.Encode(res)
+			default:
+				res.Error = fmt.Sprintf("unknown command '%s'", req.Cmd)
+			}
+		}
+		if err := out.Encode(res); err != nil {
+			return err
+		}
+	}
+	return in.Err()
+}
+
+// ServeTcp runs a new environment for each connection (see Serve).
+func ServeTcp(host, port string, c Config) error {
+	if _, err := New(c); err != nil {
+		return err // invalid config
+	}
+	l, err := net.Listen("tcp", host+":"+port)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			if err := Serve(c, conn, conn); err != nil {
+				fmt.Printf("rlenv: %v\n", err)
+			}
+		}(conn)
+	}
+}
+
+// action decodes the action of a step request
+func (e *Env) action(raw json.RawMessage) (Action, error) {
+	var a Action
+	if e.config.Action == ActionContinuous {
+		var v [2]float32
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return a, fmt.Errorf("invalid action: use [x, y]")
+		}
+		a.X, a.Y = v[0], v[1]
+		return a, nil
+	}
+	if err := json.Unmarshal(raw, &a.Index); err != nil {
+		return a, fmt.Errorf("invalid action: use an index")
+	}
+	return a, nil
+}
@@ -0,0 +1,307 @@
+package rlenv
+
+import (
+	"CloudWars/ai/simai"
+	"CloudWars/bot"
+	"CloudWars/core"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Agent is the player name of the agent.
+const Agent = "Agent"
+
+// Config is the setup of an environment (json file of the bridge).
+type Config struct {
+	// game (DEFAULT: like the server)
+	Width           int
+	Height          int
+	GameSpeed       int
+	NeutralAmount   int
+	NeutralMaxSpeed float32
+	NeutralMaxVapor float32
+	PlayerVapor     float32
+	Rules           core.Rules
+
+	FrameSkip   int                // iterations per step (DEFAULT: GameSpeed/10, the poll limit of the server)
+	Observation string             // ObservationRaw, ObservationNearest or ObservationGrid (DEFAULT: nearest)
+	Nearest     int                // nearest: number of clouds (DEFAULT: 8)
+	GridWidth   int                // grid: cells (DEFAULT: 32)
+	GridHeight  int                // grid: cells (DEFAULT: 18)
+	Action      string             // ActionDiscrete or ActionContinuous (DEFAULT: discrete)
+	Angles      int                // discrete: directions (DEFAULT: 8)
+	Strengths   []float32          // discrete: wind strengths (DEFAULT: 10, 50, 100)
+	Reward      map[string]float64 // weights of the reward functions (see Rewards, DEFAULT: vapor 1)
+	Opponents   []string           // built-in opponents: 'simai' or 'idle'
+}
+
+// Info contains additional information about a step.
+type Info struct {
+	Iteration uint64
+	Vapor     float32 // vapor of the agent
+	Invalid   bool    // the move was ignored (see core.World Move)
+	Winner    string  // the game is decided
+}
+
+// Env is a reinforcement learning environment: the agent controls a player cloud with reset and step.
+// The world advances FrameSkip iterations per step, the opponents decide at the same frequency.
+type Env struct {
+	config    Config
+	bots      []bot.Bot
+	world     *core.World
+	me        *core.Cloud
+	opponents []*opponent
+	done      bool
+	rewards   []string // sorted names of the reward functions
+}
+
+type opponent struct {
+	bot   bot.Bot
+	cloud *core.Cloud
+}
+
+// New creates an environment. The opponents are added after the built-in opponents of the config.
+func New(c Config, opponents ...bot.Bot) (*Env, error) {
+	c.defaults()
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	e := &Env{config: c}
+	for name := range c.Reward {
+		e.rewards = append(e.rewards, name)
+	}
+	sort.Strings(e.rewards)
+	colors := []string{"red", "orange", "purple", "gray"} // the agent is blue
+	for i, name := range c.Opponents {
+		if i >= len(colors) {
+			return nil, fmt.Errorf("too many opponents")
+		}
+		player := fmt.Sprintf("%s-%s", name, colors[i])
+		switch name {
+		case "simai":
+			e.bots = append(e.bots, simai.NewSimAI(player, colors[i]))
+		case "idle":
+			e.bots = append(e.bots, idle{name: player, color: colors[i]})
+		default:
+			return nil, fmt.Errorf("unknown opponent '%s'", name)
+		}
+	}
+	e.bots = append(e.bots, opponents...)
+	return e, nil
+}
+
+// Config returns the config with all defaults.
+func (e *Env) Config() Config {
+	return e.config
+}
+
+// World returns the current world.
+func (e *Env) World() *core.World {
+	return e.world
+}
+
+// Reset starts a new game and returns the first observation.
+// The same seed creates the same game (neutral clouds and start positions), the SimAI opponents are reset
+// with the seed (see simai.SimAI Reset), so the same actions of the agent result in the same episode.
+func (e *Env) Reset(seed int64) []float32 {
+	c := e.config
+	e.world = core.NewWorld(c.Width, c.Height, c.GameSpeed, c.NeutralAmount, c.NeutralMaxSpeed, c.NeutralMaxVapor, seed)
+	e.world.SetRules(c.Rules)
+	e.done = false
+
+	// players
+	rnd := rand.New(rand.NewSource(seed))
+	e.me = e.world.AddPlayer(Agent, "blue", e.position(rnd), c.PlayerVapor)
+	e.opponents = e.opponents[:0]
+	for i, b := range e.bots {
+		if s, ok := b.(*simai.SimAI); ok {
+			s.Reset(seed + int64(i) + 1)
+		}
+		cl := e.world.AddPlayer(b.Name(), b.Color(), e.position(rnd), c.PlayerVapor)
+		e.opponents = append(e.opponents, &opponent{bot: b, cloud: cl})
+	}
+
+	return e.observe()
+}
+
+// Step executes the action of the agent and the opponents and advances the world.
+// The episode is done when the agent is dead or the game is decided.
+func (e *Env) Step(a Action) (observation []float32, reward float64, done bool, info Info) {
+	if e.world == nil {
+		e.Reset(1)
+	}
+	if e.done {
+		return e.observe(), 0, true, e.info()
+	}
+	prev := e.world.Clone()
+
+	// opponents (same snapshot as the agent)
+	actions := make([]bot.Action, len(e.opponents))
+	for i, o := range e.opponents {
+		if !o.cloud.IsDeath() {
+			actions[i] = o.bot.Act(e.world.Clone())
+		}
+	}
+
+	// agent
+	invalid := false
+	if wind := a.wind(e.config); wind != nil && wind.Strength() >= 1 {
+		invalid = !e.world.Move(e.me, wind)
+	}
+	for i, o := range e.opponents {
+		if o.cloud.IsDeath() {
+			continue
+		}
+		if actions[i].Kill {
+			e.world.Kill(o.cloud)
+		} else if actions[i].Wind != nil {
+			e.world.Move(o.cloud, actions[i].Wind)
+		}
+	}
+
+	// update
+	for i := 0; i < e.config.FrameSkip; i++ {
+		e.world.Update()
+		if _, _, _, winCondition, _ := e.world.Stats(); winCondition {
+			break
+		}
+	}
+
+	// result
+	for _, name := range e.rewards {
+		reward += e.config.Reward[name] * Rewards[name](prev, e.world, Agent)
+	}
+	_, _, _, winCondition, _ := e.world.Stats()
+	e.done = winCondition || e.me.IsDeath()
+	info = e.info()
+	info.Invalid = invalid
+	return e.observe(), reward, e.done, info
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// observe encodes the world for the agent
+func (e *Env) observe() []float32 {
+	switch e.config.Observation {
+	case ObservationRaw:
+		return encodeRaw(e.world, Agent)
+	case ObservationGrid:
+		return encodeGrid(e.world, Agent, e.config)
+	default:
+		return encodeNearest(e.world, Agent, e.config)
+	}
+}
+
+// info returns the state of the game
+func (e *Env) info() Info {
+	iteration, _, _, winCondition, leader := e.world.Stats()
+	i := Info{Iteration: iteration, Vapor: e.me.Vapor}
+	if winCondition {
+		i.Winner = leader
+	}
+	return i
+}
+
+// position returns a random position without other clouds
+func (e *Env) position(rnd *rand.Rand) *core.Position {
+	r := float32(math.Sqrt(float64(e.config.PlayerVapor)))
+	for tries := 0; ; tries++ {
+		p := core.NewPosition(rnd.Float32()*float32(e.config.Width), rnd.Float32()*float32(e.config.Height))
+		free := true
+		for _, c := range e.world.Clouds() {
+			if math.Hypot(float64(c.Pos.X-p.X), float64(c.Pos.Y-p.Y)) < float64(c.Radius()+r) {
+				free = false
+				break
+			}
+		}
+		if free || tries > 1000 {
+			return p
+		}
+	}
+}
+
+// defaults sets all missing values
+func (c *Config) defaults() {
+	if c.Width == 0 {
+		c.Width = 2048
+	}
+	if c.Height == 0 {
+		c.Height = 1152
+	}
+	if c.GameSpeed == 0 {
+		c.GameSpeed = 60
+	}
+	if c.NeutralAmount == 0 {
+		c.NeutralAmount = 100
+	}
+	if c.NeutralMaxSpeed == 0 {
+		c.NeutralMaxSpeed = 7
+	}
+	if c.NeutralMaxVapor == 0 {
+		c.NeutralMaxVapor = 200
+	}
+	if c.PlayerVapor == 0 {
+		c.PlayerVapor = 600
+	}
+	if c.FrameSkip == 0 {
+		c.FrameSkip = c.GameSpeed / 10
+		if c.FrameSkip < 1 {
+			c.FrameSkip = 1
+		}
+	}
+	if c.Observation == "" {
+		c.Observation = ObservationNearest
+	}
+	if c.Nearest == 0 {
+		c.Nearest = 8
+	}
+	if c.GridWidth == 0 {
+		c.GridWidth = 32
+	}
+	if c.GridHeight == 0 {
+		c.GridHeight = 18
+	}
+	if c.Action == "" {
+		c.Action = ActionDiscrete
+	}
+	if c.Angles == 0 {
+		c.Angles = 8
+	}
+	if len(c.Strengths) == 0 {
+		c.Strengths = []float32{10, 50, 100}
+	}
+	if len(c.Reward) == 0 {
+		c.Reward = map[string]float64{"vapor": 1}
+	}
+}
+
+// validate checks the config
+func (c *Config) validate() error {
+	switch c.Observation {
+	case ObservationRaw, ObservationNearest, ObservationGrid:
+	default:
+		return fmt.Errorf("unknown observation '%s'", c.Observation)
+	}
+	if c.Action != ActionDiscrete && c.Action != ActionContinuous {
+		return fmt.Errorf("unknown action '%s'", c.Action)
+	}
+	for name := range c.Reward {
+		if Rewards[name] == nil {
+			return fmt.Errorf("unknown reward '%s'", name)
+		}
+	}
+	return nil
+}
+
+// idle is an opponent that never moves
+type idle struct {
+	name  string
+	color string
+}
+
+func (i idle) Name() string                     { return i.name }
+func (i idle) Color() string                    { return i.color }
+func (i idle) Act(world *core.World) bot.Action { return bot.Action{} }
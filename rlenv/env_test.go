@@ -0,0 +1,217 @@
+package rlenv
+
+import (
+	"CloudWars/core"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	e, err := New(Config{Width: 1000, Height: 600, NeutralAmount: 20, Opponents: []string{"idle"}})
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if s := e.ActionSpace(); s.Type != "discrete" || s.N != 1+8*3 {
+		t.Errorf("fail: %+v", s)
+	}
+	if s := e.ObservationSpace(); !reflect.DeepEqual(s.Shape, []int{5 + 6*8}) {
+		t.Errorf("fail: %+v", s)
+	}
+
+	// same seed, same game
+	obs := e.Reset(42)
+	if len(obs) != 5+6*8 || obs[4] != 1 {
+		t.Errorf("fail: %v", obs)
+	}
+	if obs2 := e.Reset(42); !reflect.DeepEqual(obs, obs2) {
+		t.Errorf("fail: %v\n%v", obs, obs2)
+	}
+	if obs2 := e.Reset(43); reflect.DeepEqual(obs, obs2) {
+		t.Errorf("fail: same observation")
+	}
+
+	// move: vapor costs
+	e.Reset(42)
+	_, reward, done, info := e.Step(Action{Index: 1 + 8}) // 50
+	if info.Iteration != 6 || done || info.Invalid || reward > -40 || reward >= 0 {
+		t.Errorf("fail: %v %v %+v", reward, done, info)
+	}
+
+	// invalid move, no move
+	_, _, _, info = e.Step(Action{Index: 1 + 8*2 + 3}) // 100: ok
+	if info.Iteration != 12 || info.Invalid {
+		t.Errorf("fail: %+v", info)
+	}
+	e.config.Strengths = []float32{10, 50, 1000}
+	_, _, _, info = e.Step(Action{Index: 1 + 8*2})
+	if !info.Invalid {
+		t.Errorf("fail: %+v", info)
+	}
+
+	// play until the end
+	for i := 0; i < 10000 && !done; i++ {
+		_, _, done, info = e.Step(Action{})
+	}
+	if !done || info.Winner == "" {
+		t.Errorf("fail: %v %+v", done, info)
+	}
+	if _, reward, done, _ := e.Step(Action{}); !done || reward != 0 {
+		t.Errorf("fail: step after the end")
+	}
+}
+
+func TestEnvSeed(t *testing.T) {
+	play := func(e *Env) [][]float32 {
+		obs := [][]float32{e.Reset(7)}
+		for i := 0; i < 70; i++ {
+			o, _, done, _ := e.Step(Action{})
+			obs = append(obs, o)
+			if done {
+				break
+			}
+		}
+		return obs
+	}
+
+	// same seed, same episode (the simai opponent is reset)
+	e, err := New(Config{Width: 1000, Height: 600, NeutralAmount: 20, Opponents: []string{"simai"}})
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	a, b := play(e), play(e)
+	if len(a) != len(b) {
+		t.Fatalf("fail: %d != %d steps", len(a), len(b))
+	}
+	for i := range a {
+		if !reflect.DeepEqual(a[i], b[i]) {
+			t.Fatalf("fail: step %d\n%v\n%v", i, a[i], b[i])
+		}
+	}
+}
+
+func TestAction(t *testing.T) {
+	c := Config{}
+	c.defaults()
+	if w := (Action{Index: 0}).wind(c); w != nil {
+		t.Errorf("fail: %v", w)
+	}
+	if w := (Action{Index: 1}).wind(c); !reflect.DeepEqual(w, core.NewVelocityByAngle(0, 10)) {
+		t.Errorf("fail: %v", w)
+	}
+	if w := (Action{Index: 1 + 8 + 2}).wind(c); !reflect.DeepEqual(w, core.NewVelocityByAngle(90, 50)) {
+		t.Errorf("fail: %v", w)
+	}
+	if w := (Action{Index: 24}).wind(c); !reflect.DeepEqual(w, core.NewVelocityByAngle(315, 100)) {
+		t.Errorf("fail: %v", w)
+	}
+	if w := (Action{Index: 25}).wind(c); w != nil {
+		t.Errorf("fail: %v", w)
+	}
+	c.Action = ActionContinuous
+	if w := (Action{X: 3, Y: 4}).wind(c); w.Strength() != 5 {
+		t.Errorf("fail: %v", w)
+	}
+}
+
+func TestObservation(t *testing.T) {
+	for _, o := range []string{ObservationRaw, ObservationNearest, ObservationGrid} {
+		e, err := New(Config{Width: 1000, Height: 600, NeutralAmount: 20, Observation: o})
+		if err != nil {
+			t.Fatalf("fail: %v", err)
+		}
+		obs := e.Reset(1)
+		size := 1
+		for _, s := range e.ObservationSpace().Shape {
+			size *= s
+		}
+		if size < 0 {
+			size = -size * len(e.World().Clouds()) // raw: variable length
+		}
+		if len(obs) != size {
+			t.Errorf("fail: %s: %d != %d", o, len(obs), size)
+		}
+	}
+
+	// grid: the agent is in channel 0
+	c := Config{Width: 320, Height: 180, GridWidth: 32, GridHeight: 18, PlayerVapor: 100}
+	w := core.NewWorld(320, 180, 60, 0, 0, 0, 1)
+	w.AddPlayer(Agent, "blue", core.NewPosition(55, 55), 100) // radius 10
+	obs := encodeGrid(w, Agent, c)
+	var sum float32
+	for _, v := range obs[:32*18] {
+		sum += v
+	}
+	if sum < 3 || sum > 5 || obs[5*32+5] != 1 {
+		t.Errorf("fail: %v", sum)
+	}
+	if _, err := New(Config{Observation: "image"}); err == nil {
+		t.Errorf("fail: unknown observation")
+	}
+}
+
+func TestRewards(t *testing.T) {
+	prev := core.NewWorld(1000, 600, 60, 0, 0, 0, 1)
+	prev.AddPlayer(Agent, "blue", core.NewPosition(100, 100), 600)
+	prev.AddPlayer("B", "red", core.NewPosition(800, 100), 600)
+	next := prev.Clone()
+	next.Move(next.Me(Agent), core.NewVelocity(50, 0))
+
+	if r := VaporDelta(prev, next, Agent); r != -50 {
+		t.Errorf("fail: %v", r)
+	}
+	if r := Survival(prev, next, Agent); r != 1 {
+		t.Errorf("fail: %v", r)
+	}
+	if r := Win(prev, next, Agent); r != 0 {
+		t.Errorf("fail: %v", r)
+	}
+	next.Kill(next.Me(Agent))
+	if r := Win(prev, next, Agent); r != -1 {
+		t.Errorf("fail: %v", r)
+	}
+	if _, err := New(Config{Reward: map[string]float64{"fun": 1}}); err == nil {
+		t.Errorf("fail: unknown reward")
+	}
+}
+
+func TestServe(t *testing.T) {
+	in := strings.Join([]string{
+		`{"Cmd":"spaces"}`,
+		`{"Cmd":"reset","Seed":7}`,
+		`{"cmd":"step","action":[30,40]}`,
+		`{"Cmd":"step","Action":3}`,
+		`{"Cmd":"fly"}`,
+		`{"Cmd":"close"}`,
+		`{"Cmd":"spaces"}`,
+	}, "\n")
+	out := new(bytes.Buffer)
+	if err := Serve(Config{Width: 1000, Height: 600, NeutralAmount: 20, Action: ActionContinuous}, strings.NewReader(in), out); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("fail: %v", lines)
+	}
+	res := make([]response, len(lines))
+	for i, l := range lines {
+		if err := json.Unmarshal([]byte(l), &res[i]); err != nil {
+			t.Fatalf("fail: %v", err)
+		}
+	}
+	if res[0].ActionSpace.Type != "box" || res[0].ObservationSpace.Shape[0] != 53 {
+		t.Errorf("fail: %s", lines[0])
+	}
+	if len(res[1].Observation) != 53 || res[1].Info.Vapor != 600 {
+		t.Errorf("fail: %s", lines[1])
+	}
+	if res[2].Reward != -50 || res[2].Info.Iteration != 6 || res[2].Error != "" {
+		t.Errorf("fail: %s", lines[2])
+	}
+	if res[3].Error == "" || res[4].Error == "" || res[5].Error != "" {
+		t.Errorf("fail: %s %s %s", lines[3], lines[4], lines[5])
+	}
+}
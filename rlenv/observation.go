@@ -0,0 +1,131 @@
+package rlenv
+
+import (
+	"CloudWars/core"
+	"math"
+	"sort"
+)
+
+const (
+	ObservationRaw     = "raw"     // all clouds: x, y, vx, vy, vapor, me, player (7 values per cloud)
+	ObservationNearest = "nearest" // agent and the k nearest clouds, normalized (5 + 6k values)
+	ObservationGrid    = "grid"    // occupancy grid with 3 channels: agent, players, neutral (3 x height x width)
+)
+
+// ObservationSpace returns the observation space of the environment.
+func (e *Env) ObservationSpace() Space {
+	c := e.config
+	switch c.Observation {
+	case ObservationRaw:
+		return Space{Type: "box", Shape: []int{-1, 7}}
+	case ObservationGrid:
+		return Space{Type: "box", Shape: []int{3, c.GridHeight, c.GridWidth}, Low: 0}
+	default:
+		return Space{Type: "box", Shape: []int{5 + 6*c.Nearest}}
+	}
+}
+
+// encodeRaw returns all clouds (flat list)
+func encodeRaw(w *core.World, name string) []float32 {
+	clouds := w.Clouds()
+	obs := make([]float32, 0, len(clouds)*7)
+	for _, c := range clouds {
+		obs = append(obs, c.Pos.X, c.Pos.Y, c.Vel.X, c.Vel.Y, c.Vapor, flag(c.Player == name), flag(c.Player != ""))
+	}
+	return obs
+}
+
+// encodeNearest returns the agent (x, y, vx, vy, vapor) and the k nearest living clouds
+// (dx, dy, dvx, dvy, vapor relative to the agent, player). Missing clouds are zeros.
+// Positions are divided by the board size, velocities by 10 and the agent vapor by the player vapor.
+func encodeNearest(w *core.World, name string, c Config) []float32 {
+	obs := make([]float32, 5+6*c.Nearest)
+	me := w.Me(name)
+	if me == nil || me.IsDeath() {
+		return obs
+	}
+	width, height := float32(w.Width()), float32(w.Height())
+	copy(obs, []float32{me.Pos.X / width, me.Pos.Y / height, me.Vel.X / 10, me.Vel.Y / 10, me.Vapor / c.PlayerVapor})
+
+	// nearest clouds
+	others := make([]*core.Cloud, 0)
+	for _, o := range w.Clouds() {
+		if o.Player != name && !o.IsDeath() {
+			others = append(others, o)
+		}
+	}
+	dist := func(o *core.Cloud) float64 {
+		return math.Hypot(float64(o.Pos.X-me.Pos.X), float64(o.Pos.Y-me.Pos.Y))
+	}
+	sort.SliceStable(others, func(i, j int) bool { return dist(others[i]) < dist(others[j]) })
+
+	for i, o := range others {
+		if i >= c.Nearest {
+			break
+		}
+		copy(obs[5+6*i:], []float32{
+			(o.Pos.X - me.Pos.X) / width, (o.Pos.Y - me.Pos.Y) / height,
+			(o.Vel.X - me.Vel.X) / 10, (o.Vel.Y - me.Vel.Y) / 10,
+			o.Vapor / me.Vapor, flag(o.Player != ""),
+		})
+	}
+	return obs
+}
+
+// encodeGrid returns the occupancy grid (channel, row, column).
+// Each cell covered by a cloud (cell center within the radius, at least the cell of the cloud center)
+// gets the vapor of the cloud divided by the player vapor.
+func encodeGrid(w *core.World, name string, c Config) []float32 {
+	gw, gh := c.GridWidth, c.GridHeight
+	obs := make([]float32, 3*gw*gh)
+	cellW := float32(w.Width()) / float32(gw)
+	cellH := float32(w.Height()) / float32(gh)
+
+	for _, o := range w.Clouds() {
+		if o.IsDeath() {
+			continue
+		}
+		channel := 2 // neutral
+		if o.Player == name {
+			channel = 0
+		} else if o.Player != "" {
+			channel = 1
+		}
+		v := o.Vapor / c.PlayerVapor
+		r := o.Radius()
+
+		// bounding box of the cloud
+		cx, cy := clamp(int(o.Pos.X/cellW), gw), clamp(int(o.Pos.Y/cellH), gh)
+		x0, x1 := clamp(int((o.Pos.X-r)/cellW), gw), clamp(int((o.Pos.X+r)/cellW), gw)
+		y0, y1 := clamp(int((o.Pos.Y-r)/cellH), gh), clamp(int((o.Pos.Y+r)/cellH), gh)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				dx := (float32(x)+0.5)*cellW - o.Pos.X
+				dy := (float32(y)+0.5)*cellH - o.Pos.Y
+				if (x == cx && y == cy) || dx*dx+dy*dy <= r*r {
+					obs[(channel*gh+y)*gw+x] += v
+				}
+			}
+		}
+	}
+	return obs
+}
+
+// flag converts a bool to 0 or 1
+func flag(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// clamp limits a cell index to [0, n-1]
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
@@ -0,0 +1,50 @@
+package rlenv
+
+import (
+	"CloudWars/core"
+)
+
+// RewardFunc calculates the reward of a step from the world before and after the step.
+type RewardFunc func(prev, next *core.World, name string) float64
+
+// Rewards are the reward functions that can be used in Config Reward (add your own before New).
+var Rewards = map[string]RewardFunc{
+	"vapor":    VaporDelta,
+	"survival": Survival,
+	"win":      Win,
+}
+
+// VaporDelta is the vapor gained (or lost) by the agent.
+func VaporDelta(prev, next *core.World, name string) float64 {
+	return float64(vapor(next, name) - vapor(prev, name))
+}
+
+// Survival is 1 for each step the agent is alive.
+func Survival(prev, next *core.World, name string) float64 {
+	if vapor(next, name) >= 1 {
+		return 1
+	}
+	return 0
+}
+
+// Win is 1 when the game is decided and the agent is the winner, -1 when another player wins or the agent dies.
+func Win(prev, next *core.World, name string) float64 {
+	if vapor(prev, name) >= 1 && vapor(next, name) < 1 {
+		return -1 // dead
+	}
+	if _, _, _, winCondition, leader := next.Stats(); winCondition {
+		if leader == name {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// vapor returns the vapor of a player (0: dead)
+func vapor(w *core.World, name string) float32 {
+	if me := w.Me(name); me != nil && !me.IsDeath() {
+		return me.Vapor
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+package stats
+
+import (
+	"CloudWars/core"
+	"math"
+	"sync"
+)
+
+// Collector records the statistics of all players of a world (see core.World AddListener).
+type Collector struct {
+	world    *core.World
+	interval uint64 // vapor samples every n iterations
+	remove   func()
+	done     chan struct{}
+
+	mux        sync.Mutex
+	players    []*Player          // in order of appearance
+	byName     map[string]*Player // players by name
+	last       map[string]core.Position
+	iterations uint64
+	evaporated float32 // see core.World VaporBalance
+	rained     float32
+	winner     string
+	closed     bool // done is closed
+}
+
+// NewCollector starts the recording of the statistics.
+// The vapor of each player is sampled once per second (game time).
+func NewCollector(world *core.World) *Collector {
+	interval := uint64(world.GameSpeed())
+	if interval == 0 {
+		interval = 60
+	}
+
+	c := &Collector{
+		world:    world,
+		interval: interval,
+		done:     make(chan struct{}),
+		byName:   make(map[string]*Player),
+		last:     make(map[string]core.Position),
+	}
+
+	// players that are already in the game
+	c.sample(world.Clouds(), true)
+
+	c.remove = world.AddListener(c.handle)
+	return c
+}
+
+// Close stops the recording.
+func (c *Collector) Close() {
+	c.remove()
+}
+
+// Done is closed when the game is decided (after the last update of the game).
+func (c *Collector) Done() <-chan struct{} {
+	return c.done
+}
+
+// Report returns the statistics of the game so far.
+func (c *Collector) Report() *Report {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	r := &Report{
+		Iterations: c.iterations,
+		Seconds:    float32(c.iterations) / float32(c.world.GameSpeed()),
+		Winner:     c.winner,
+		Interval:   c.interval,
+		Evaporated: c.evaporated,
+		Rained:     c.rained,
+		Players:    make([]*Player, 0, len(c.players)),
+	}
+	for _, p := range c.players {
+		cp := *p
+		cp.Vapor = append([]float32(nil), p.Vapor...)
+		r.Players = append(r.Players, &cp)
+	}
+	return r
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// handle is the event listener
+func (c *Collector) handle(e core.Event) {
+	// tick: the listener runs outside the world lock
+	var clouds []*core.Cloud
+	var evaporated, rained float32
+	if e.Kind == core.EventTick {
+		clouds = c.world.Clouds()
+		evaporated, rained = c.world.VaporBalance()
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	switch e.Kind {
+	case core.EventTick:
+		c.iterations = e.Iteration
+		c.evaporated, c.rained = evaporated, rained
+		c.sample(clouds, e.Iteration%c.interval == 0)
+		if c.winner != "" && !c.closed {
+			c.closed = true
+			close(c.done) // after the last update of the game
+		}
+
+	case core.EventSpawn:
+		if e.Player != "" {
+			c.player(e.Player)
+			c.last[e.Player] = core.Position{X: e.X, Y: e.Y}
+		}
+
+	case core.EventMove:
+		if p := c.byName[e.Player]; p != nil {
+			p.Moves++
+			p.Spent += e.Amount
+		}
+
+	case core.EventReject:
+		if p := c.byName[e.Player]; p != nil {
+			p.InvalidMoves++
+		}
+
+	case core.EventAbsorb:
+		if p := c.byName[e.Player]; p != nil {
+			if e.OtherPlayer == "" {
+				p.AbsorbedNeutral += e.Amount
+			} else {
+				p.AbsorbedPlayer += e.Amount
+			}
+		}
+		if p := c.byName[e.OtherPlayer]; p != nil {
+			p.Lost += e.Amount
+		}
+
+	case core.EventDeath:
+		if p := c.byName[e.Player]; p != nil {
+			p.Deaths++
+		}
+		if p := c.byName[e.OtherPlayer]; p != nil && e.OtherPlayer != e.Player {
+			p.Kills++
+		}
+
+	case core.EventWinner:
+		if len(c.byName) > 0 {
+			c.winner = e.Player // a world without players (e.g. waiting for the players) is no game
+		}
+	}
+}
+
+// sample updates distance, peak and final vapor of all player clouds (not thread-safe).
+// With vapor, the current vapor is added to the time series.
+func (c *Collector) sample(clouds []*core.Cloud, vapor bool) {
+	for _, cl := range clouds {
+		if cl.Player == "" {
+			continue
+		}
+		p := c.player(cl.Player)
+		p.Color = cl.Color
+		p.FinalVapor = cl.Vapor
+		if cl.Vapor > p.PeakVapor {
+			p.PeakVapor = cl.Vapor
+		}
+		if vapor {
+			p.Vapor = append(p.Vapor, cl.Vapor)
+		}
+
+		// distance travelled
+		if last, ok := c.last[cl.Player]; ok && !cl.IsDeath() {
+			p.Distance += float32(math.Hypot(float64(cl.Pos.X-last.X), float64(cl.Pos.Y-last.Y)))
+		}
+		c.last[cl.Player] = *cl.Pos
+	}
+}
+
+// player returns the statistics of a player (not thread-safe).
+func (c *Collector) player(name string) *Player {
+	p := c.byName[name]
+	if p == nil {
+		p = &Player{Name: name}
+		c.byName[name] = p
+		c.players = append(c.players, p)
+	}
+	return p
+}
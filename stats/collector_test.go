@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"CloudWars/core"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCollector(t *testing.T) {
+	w := core.NewWorld(1000, 1000, 60, 0, 0, 0, 0)
+	p1 := w.AddPlayer("Player 1", "red", core.NewPosition(200, 500), 100)
+	col := NewCollector(w)
+	defer col.Close()
+	p2 := w.AddPlayer("Player 2", "blue", core.NewPosition(800, 500), 400)
+
+	// moves
+	w.Move(p1, core.NewVelocity(10, 0))
+	w.Move(p1, core.NewVelocity(1000, 0)) // invalid
+	w.Move(p2, core.NewVelocity(-30, 0))
+	for i := 0; i < 120; i++ {
+		w.Update()
+	}
+
+	// collision
+	p1.Pos.X = p2.Pos.X
+	p1.Pos.Y = p2.Pos.Y
+	w.Update()
+	select {
+	case <-col.Done():
+	default:
+		t.Fatalf("fail: not done")
+	}
+
+	r := col.Report()
+	if r.Winner != "Player 2" || r.Iterations != 121 || len(r.Players) != 2 {
+		t.Fatalf("fail: %+v", r)
+	}
+	s1, s2 := r.Players[0], r.Players[1]
+	if s1.Name != "Player 1" || s1.Color != "red" || s1.Moves != 1 || s1.InvalidMoves != 1 || s1.Spent != 10 || s1.Deaths != 1 || s1.Lost == 0 || s1.Distance <= 0 {
+		t.Errorf("fail: %+v", s1)
+	}
+	if s2.Moves != 1 || s2.Spent != 30 || s2.Kills != 1 || s2.AbsorbedPlayer != s1.Lost || s2.PeakVapor != s2.FinalVapor || len(s2.Vapor) != 2 {
+		t.Errorf("fail: %+v", s2)
+	}
+
+	// vapor balance
+	if evaporated, rained := w.VaporBalance(); r.Evaporated != evaporated || r.Rained != rained {
+		t.Errorf("fail: %v %v", r.Evaporated, r.Rained)
+	}
+
+	// output
+	var jr Report
+	if err := json.Unmarshal([]byte(r.Json()), &jr); err != nil || jr.Winner != r.Winner {
+		t.Errorf("fail: %v", err)
+	}
+	if md := r.Markdown(); !strings.Contains(md, "Winner: **Player 2**") || !strings.Contains(md, "| Player 1 |") || !strings.Contains(md, "Vapor balance:") {
+		t.Errorf("fail: %s", md)
+	}
+}
+
+func TestCollector_weather(t *testing.T) {
+	w := core.NewWorld(1000, 1000, 60, 10, 0, 100, 0)
+	w.SetRules(core.Rules{Weather: core.Weather{EvaporationRate: 5, EvaporationSize: 40, RainInterval: 0.5, RainMinVapor: 10, RainMaxVapor: 80, Seed: 7}})
+	w.AddPlayer("Player 1", "red", core.NewPosition(200, 500), 100)
+	col := NewCollector(w)
+	defer col.Close()
+	for i := 0; i < 180; i++ {
+		w.Update()
+	}
+
+	r := col.Report()
+	evaporated, rained := w.VaporBalance()
+	if r.Evaporated <= 0 || r.Rained <= 0 || r.Evaporated != evaporated || r.Rained != rained {
+		t.Errorf("fail: %v %v", r.Evaporated, r.Rained)
+	}
+}
+
+func TestCollector_noPlayers(t *testing.T) {
+	// a server world before the players join
+	w := core.NewWorld(1000, 1000, 60, 10, 0, 100, 0)
+	col := NewCollector(w)
+	defer col.Close()
+	w.Update()
+	w.Update()
+	select {
+	case <-col.Done():
+		t.Fatalf("fail: done without players")
+	default:
+	}
+
+	// the game starts with the players
+	w.AddPlayer("Player 1", "red", core.NewPosition(200, 500), 100)
+	w.Update()
+	w.Me("Player 1").Vapor = 0
+	w.Update()
+	w.Update()
+	if r := col.Report(); r.Winner != "no player alive" {
+		t.Errorf("fail: %+v", r)
+	}
+}
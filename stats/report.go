@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Report contains the statistics of a game.
+type Report struct {
+	Iterations uint64    // game duration in iterations
+	Seconds    float32   // game duration in seconds
+	Winner     string    // empty if the game is not decided
+	Interval   uint64    // iterations between two vapor samples
+	Evaporated float32   // vapor that has left the game (see core.World VaporBalance)
+	Rained     float32   // vapor added by rain
+	Players    []*Player // in order of appearance
+}
+
+// Player contains the statistics of one player.
+type Player struct {
+	Name            string
+	Color           string
+	Vapor           []float32 // vapor over time (see Report.Interval)
+	PeakVapor       float32
+	FinalVapor      float32
+	Spent           float32 // vapor spent on moves
+	AbsorbedNeutral float32 // vapor absorbed from neutral clouds
+	AbsorbedPlayer  float32 // vapor absorbed from other players
+	Lost            float32 // vapor absorbed by other clouds
+	Kills           int
+	Deaths          int
+	Distance        float32 // distance travelled
+	Moves           int
+	InvalidMoves    int
+}
+
+// Json returns the report as json.
+func (r *Report) Json() string {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		fmt.Printf("ERROR: Json: %v\n", err)
+	}
+	return string(b)
+}
+
+// Markdown returns the report as a markdown document.
+func (r *Report) Markdown() string {
+	sb := new(strings.Builder)
+
+	sb.WriteString("# CloudWars match report\n\n")
+	if r.Winner != "" {
+		fmt.Fprintf(sb, "Winner: **%s** after %d iterations (%.1f s)\n\n", r.Winner, r.Iterations, r.Seconds)
+	} else {
+		fmt.Fprintf(sb, "Not decided after %d iterations (%.1f s)\n\n", r.Iterations, r.Seconds)
+	}
+	fmt.Fprintf(sb, "Vapor balance: %.0f evaporated, %.0f rained\n\n", r.Evaporated, r.Rained)
+
+	sb.WriteString("| Player | Final | Peak | Spent | Absorbed (neutral) | Absorbed (players) | Lost | Kills | Deaths | Distance | Moves | Invalid |\n")
+	sb.WriteString("|--------|------:|-----:|------:|-------------------:|-------------------:|-----:|------:|-------:|---------:|------:|--------:|\n")
+	for _, p := range r.Players {
+		fmt.Fprintf(sb, "| %s | %.0f | %.0f | %.0f | %.0f | %.0f | %.0f | %d | %d | %.0f | %d | %d |\n",
+			p.Name, p.FinalVapor, p.PeakVapor, p.Spent, p.AbsorbedNeutral, p.AbsorbedPlayer, p.Lost,
+			p.Kills, p.Deaths, p.Distance, p.Moves, p.InvalidMoves)
+	}
+
+	sb.WriteString("\n## Vapor over time\n\n```\n")
+	for _, p := range r.Players {
+		fmt.Fprintf(sb, "%-25s %s\n", p.Name, sparkline(p.Vapor, r.peak()))
+	}
+	sb.WriteString("```\n")
+
+	return sb.String()
+}
+
+// Write saves the report as path.json and path.md.
+func (r *Report) Write(path string) error {
+	if err := os.WriteFile(path+".json", []byte(r.Json()+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(path+".md", []byte(r.Markdown()), 0644)
+}
+
+// peak returns the highest vapor of all players
+func (r *Report) peak() float32 {
+	var peak float32
+	for _, p := range r.Players {
+		if p.PeakVapor > peak {
+			peak = p.PeakVapor
+		}
+	}
+	return peak
+}
+
+// sparkline draws the values with block characters (relative to max)
+func sparkline(values []float32, max float32) string {
+	blocks := []rune(" ▁▂▃▄▅▆▇█")
+	line := make([]rune, 0, len(values))
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = int(v / max * float32(len(blocks)-1))
+		}
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(blocks) {
+			i = len(blocks) - 1
+		}
+		line = append(line, blocks[i])
+	}
+	return string(line)
+}
@@ -0,0 +1,93 @@
+package tournament
+
+import (
+	"sort"
+)
+
+// roundRobin returns all groups of n bots (indices into the bot list).
+func roundRobin(bots, n int) [][]int {
+	var groups [][]int
+	group := make([]int, 0, n)
+	var next func(start int)
+	next = func(start int) {
+		if len(group) == n {
+			groups = append(groups, append([]int(nil), group...))
+			return
+		}
+		for i := start; i < bots; i++ {
+			group = append(group, i)
+			next(i + 1)
+			group = group[:len(group)-1]
+		}
+	}
+	next(0)
+	return groups
+}
+
+// swiss returns the groups of n bots of the next round and the bots without a match (bye).
+// The bots are ordered by points and each group is filled with the best bots that have
+// not played each other yet (if possible). The byes go to the lowest bots with the fewest byes so far.
+func swiss(points []float64, byes []int, played map[[2]int]bool, n int) (groups [][]int, bye []int) {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return points[order[i]] > points[order[j]]
+	})
+
+	// bye
+	for r := len(order) % n; r > 0; r-- {
+		k := len(order) - 1
+		for i := len(order) - 1; i >= 0; i-- {
+			if byes[order[i]] < byes[order[k]] {
+				k = i
+			}
+		}
+		bye = append(bye, order[k])
+		order = append(order[:k], order[k+1:]...)
+	}
+
+	used := make([]bool, len(points))
+	for _, first := range order {
+		if used[first] {
+			continue
+		}
+		group := []int{first}
+		used[first] = true
+
+		// prefer new opponents, else the next bots by points
+		for _, fresh := range []bool{true, false} {
+			for _, b := range order {
+				if len(group) == n {
+					break
+				}
+				if used[b] || (fresh && playedAny(played, group, b)) {
+					continue
+				}
+				group = append(group, b)
+				used[b] = true
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, bye
+}
+
+// playedAny returns true if the bot has played against any bot of the group
+func playedAny(played map[[2]int]bool, group []int, b int) bool {
+	for _, g := range group {
+		if played[pair(g, b)] {
+			return true
+		}
+	}
+	return false
+}
+
+// pair returns the key of two bots for the played map
+func pair(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
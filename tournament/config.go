@@ -0,0 +1,132 @@
+package tournament
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	FormatRoundRobin = "roundrobin" // every group of bots plays every map & seed
+	FormatSwiss      = "swiss"      // bots with similar points play each other
+)
+
+// Config is the setup of a tournament (json file).
+type Config struct {
+	Format   string   // FormatRoundRobin or FormatSwiss
+	Rounds   int      // swiss: number of rounds (DEFAULT: 3)
+	Players  int      // bots per match (DEFAULT: 2)
+	Maps     []string // rules files, "" is the classic game (DEFAULT: classic game)
+	Seeds    []int64  // world seeds, every match is played with each map & seed (DEFAULT: 1)
+	Timeout  int      // game time limit of a match in seconds (DEFAULT: 300)
+	Lockstep int      // lock-step mode: max wait for the bots per step in ms (DEFAULT: 0 = real time)
+	Output   string   // directory for reports, logs and standings (DEFAULT: tournament)
+	Server   []string // server command (DEFAULT: this executable)
+
+	// game
+	Width           int // DEFAULT: 2048
+	Height          int // DEFAULT: 1152
+	Speed           int // DEFAULT: 60
+	PlayerVapor     int // DEFAULT: 600
+	NeutralAmount   int // DEFAULT: 100
+	NeutralMaxSpeed int // DEFAULT: 7
+	NeutralMaxVapor int // DEFAULT: 200
+
+	Bots []Bot
+}
+
+// Bot is a participant of a tournament.
+// The command is started with the arguments '-host <host> -port <port>' appended.
+// The name has to be the player name the bot uses in the game.
+type Bot struct {
+	Name    string
+	Command []string
+}
+
+// LoadConfig reads a tournament file and sets the defaults.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	c.defaults()
+	if err := c.validate(); err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// defaults sets all missing values
+func (c *Config) defaults() {
+	if c.Format == "" {
+		c.Format = FormatRoundRobin
+	}
+	if c.Rounds == 0 {
+		c.Rounds = 3
+	}
+	if c.Players == 0 {
+		c.Players = 2
+	}
+	if len(c.Maps) == 0 {
+		c.Maps = []string{""}
+	}
+	if len(c.Seeds) == 0 {
+		c.Seeds = []int64{1}
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 300
+	}
+	if c.Output == "" {
+		c.Output = "tournament"
+	}
+	if c.Width == 0 {
+		c.Width = 2048
+	}
+	if c.Height == 0 {
+		c.Height = 1152
+	}
+	if c.Speed == 0 {
+		c.Speed = 60
+	}
+	if c.PlayerVapor == 0 {
+		c.PlayerVapor = 600
+	}
+	if c.NeutralAmount == 0 {
+		c.NeutralAmount = 100
+	}
+	if c.NeutralMaxSpeed == 0 {
+		c.NeutralMaxSpeed = 7
+	}
+	if c.NeutralMaxVapor == 0 {
+		c.NeutralMaxVapor = 200
+	}
+}
+
+// validate checks the config
+func (c *Config) validate() error {
+	if c.Format != FormatRoundRobin && c.Format != FormatSwiss {
+		return fmt.Errorf("unknown format '%s'", c.Format)
+	}
+	if c.Players < 2 {
+		return errors.New("a match needs at least two players")
+	}
+	if len(c.Bots) < c.Players {
+		return fmt.Errorf("%d bots are not enough for matches of %d players", len(c.Bots), c.Players)
+	}
+	names := make(map[string]bool)
+	for _, b := range c.Bots {
+		if b.Name == "" || len(b.Command) == 0 {
+			return errors.New("every bot needs a name and a command")
+		}
+		if names[b.Name] {
+			return fmt.Errorf("duplicate bot name '%s'", b.Name)
+		}
+		names[b.Name] = true
+	}
+	return nil
+}
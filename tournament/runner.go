@@ -0,0 +1,300 @@
+package tournament
+
+import (
+	"CloudWars/rating"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// grace is the additional wall clock time of a match (startup, bot connections)
+const grace = 60 * time.Second
+
+// Tournament is the state of a running or finished tournament.
+type Tournament struct {
+	Config    Config
+	Matches   []*Match
+	Standings []*Standing // sorted after the last match
+
+	ledger string // optional (see rating.Update)
+}
+
+// Run plays a tournament. Each match is a headless server process with the bots as additional processes.
+// With a ledger path, every match is also added to the rating ledger (see rating.Ledger).
+// Reports, logs and the standings (standings.md & tournament.json) are written to the output directory.
+func Run(c Config, ledger string) (*Tournament, error) {
+	c.defaults()
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	t := &Tournament{Config: c}
+	for _, b := range c.Bots {
+		t.Standings = append(t.Standings, &Standing{Name: b.Name})
+	}
+
+	// server command
+	if len(c.Server) == 0 {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		t.Config.Server = []string{exe}
+	}
+
+	// output & ledger
+	if err := os.MkdirAll(c.Output, 0755); err != nil {
+		return nil, err
+	}
+	if ledger != "" {
+		if _, err := rating.Load(ledger); err != nil {
+			return nil, err
+		}
+		t.ledger = ledger
+	}
+
+	// play
+	if c.Format == FormatSwiss {
+		played := make(map[[2]int]bool)
+		for round := 1; round <= c.Rounds; round++ {
+			score := make([]float64, len(t.Standings))
+			byes := make([]int, len(t.Standings))
+			for i, s := range t.Standings {
+				score[i] = s.Points
+				byes[i] = s.Byes
+			}
+			groups, bye := swiss(score, byes, played, c.Players)
+			for _, b := range bye {
+				t.Standings[b].Byes++
+				t.Standings[b].Points += float64(c.Players - 1)
+			}
+			for _, g := range groups {
+				for _, a := range g {
+					for _, b := range g {
+						if a != b {
+							played[pair(a, b)] = true
+						}
+					}
+				}
+				t.playGroup(round, g)
+			}
+		}
+	} else {
+		for _, g := range roundRobin(len(c.Bots), c.Players) {
+			t.playGroup(1, g)
+		}
+	}
+
+	// standings
+	sortStandings(t.Standings)
+	if err := t.write(); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// playGroup plays all maps & seeds with a group of bots (indices)
+func (t *Tournament) playGroup(round int, group []int) {
+	for _, m := range t.Config.Maps {
+		for _, seed := range t.Config.Seeds {
+			match := &Match{ID: len(t.Matches) + 1, Round: round, Map: m, Seed: seed}
+			for _, i := range group {
+				match.Bots = append(match.Bots, t.Config.Bots[i].Name)
+			}
+			t.Matches = append(t.Matches, match)
+
+			fmt.Printf("MATCH %d (round %d): %v  map '%s'  seed %d\n", match.ID, round, match.Bots, m, seed)
+			if err := t.play(match, group); err != nil {
+				match.Error = err.Error()
+				fmt.Printf("MATCH %d failed: %v\n", match.ID, err)
+			}
+
+			// points
+			if match.Error != "" {
+				for _, i := range group {
+					t.Standings[i].Games++
+					t.Standings[i].Errors++
+				}
+				continue
+			}
+			p := points(match.Results)
+			for k, r := range match.Results {
+				s := t.standing(r.Name)
+				s.Games++
+				s.Points += p[k]
+				if r.Rank == 1 {
+					s.Wins++
+				}
+			}
+			fmt.Printf("MATCH %d result: %v\n", match.ID, match.Results)
+
+			// rating
+			if t.ledger != "" {
+				err := rating.Update(t.ledger, func(l *rating.Ledger) error {
+					_, err := l.AddGame(match.Results)
+					return err
+				})
+				if err != nil {
+					fmt.Printf("MATCH %d rating: %v\n", match.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// play runs the server and the bots of a match and reads the match report
+func (t *Tournament) play(m *Match, group []int) error {
+	c := t.Config
+	m.Report = filepath.Join(c.Output, fmt.Sprintf("match-%03d", m.ID))
+
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	// wall clock limit (lock-step: 10 steps per second game time)
+	limit := time.Duration(c.Timeout) * time.Second
+	if c.Lockstep > 0 {
+		limit = time.Duration(c.Timeout*10*c.Lockstep) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), limit+grace)
+	defer cancel()
+
+	// server
+	args := append([]string(nil), c.Server[1:]...)
+	args = append(args,
+		"-mode=server", "-headless=true", "-host=localhost", "-port="+port,
+		"-width="+strconv.Itoa(c.Width), "-height="+strconv.Itoa(c.Height), "-speed="+strconv.Itoa(c.Speed),
+		"-pVapor="+strconv.Itoa(c.PlayerVapor), "-nAmount="+strconv.Itoa(c.NeutralAmount),
+		"-nSpeed="+strconv.Itoa(c.NeutralMaxSpeed), "-nVapor="+strconv.Itoa(c.NeutralMaxVapor),
+		"-rules="+m.Map, "-seed="+strconv.FormatInt(m.Seed, 10), "-timeout="+strconv.Itoa(c.Timeout),
+		"-lockstep="+strconv.Itoa(c.Lockstep), "-rAmount="+strconv.Itoa(len(group)), "-report="+m.Report)
+	server, err := start(ctx, m.Report+"-server.log", c.Server[0], args)
+	if err != nil {
+		return fmt.Errorf("server: %v", err)
+	}
+	if err := waitPort(ctx, port); err != nil {
+		_ = server.Process.Kill()
+		_ = server.Wait()
+		return fmt.Errorf("server: %v", err)
+	}
+
+	// bots
+	bots := make([]*exec.Cmd, 0, len(group))
+	failed := make(map[string]bool)
+	for _, i := range group {
+		b := c.Bots[i]
+		args := append(append([]string(nil), b.Command[1:]...), "-host", "localhost", "-port", port)
+		cmd, err := start(ctx, fmt.Sprintf("%s-%s.log", m.Report, b.Name), b.Command[0], args)
+		if err != nil {
+			fmt.Printf("MATCH %d bot %s: %v\n", m.ID, b.Name, err)
+			failed[b.Name] = true
+			continue
+		}
+		bots = append(bots, cmd)
+	}
+
+	// forfeit: the server waits for all players, so the match can't start
+	if len(failed) > 0 {
+		_ = server.Process.Kill()
+	}
+
+	// wait for the end of the game, then stop the bots
+	errServer := server.Wait()
+	for _, cmd := range bots {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}
+	if len(failed) > 0 {
+		m.Results = forfeit(m.Bots, failed)
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New("timeout")
+	}
+	if errServer != nil {
+		return fmt.Errorf("server: %v", errServer)
+	}
+
+	// result
+	r, err := rating.LoadReport(m.Report + ".json")
+	if err != nil {
+		return err
+	}
+	m.Results = results(r, m.Bots)
+	return nil
+}
+
+// standing returns the standing of a bot
+func (t *Tournament) standing(name string) *Standing {
+	for _, s := range t.Standings {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// write saves the standings (markdown) and the tournament (json)
+func (t *Tournament) write() error {
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(t.Config.Output, "tournament.json"), append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	md := "# CloudWars tournament\n\n" + t.Table()
+	return os.WriteFile(filepath.Join(t.Config.Output, "standings.md"), []byte(md), 0644)
+}
+
+// start runs a command with the output redirected to a log file
+func start(ctx context.Context, log, name string, args []string) (*exec.Cmd, error) {
+	f, err := os.Create(log)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = f
+	cmd.Stderr = f
+	err = cmd.Start()
+	_ = f.Close() // the process has its own file descriptor
+	if err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// freePort returns a free tcp port of localhost
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+// waitPort waits until the server accepts connections
+func waitPort(ctx context.Context, port string) error {
+	for {
+		conn, err := net.DialTimeout("tcp", "localhost:"+port, time.Second)
+		if err == nil {
+			_, _ = conn.Write([]byte("quit\n"))
+			return conn.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package tournament
+
+import (
+	"CloudWars/rating"
+	"CloudWars/stats"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Match is one game of a tournament.
+type Match struct {
+	ID      int
+	Round   int
+	Map     string // rules file
+	Seed    int64
+	Bots    []string
+	Results []rating.Result // empty if the match failed
+	Report  string          // match report (path without extension)
+	Error   string          `json:",omitempty"`
+}
+
+// Standing is the tournament result of one bot.
+// A bot gets one point for each opponent with a worse rank in a match and half a point for each tie.
+type Standing struct {
+	Name   string
+	Points float64
+	Games  int
+	Wins   int // first rank
+	Byes   int // rounds without a match (swiss), counted as win against all opponents
+	Errors int // failed matches
+}
+
+// results returns the placements of the bots of a match report.
+// Players that are not part of the match are ignored, missing bots share the last rank.
+func results(r *stats.Report, bots []string) []rating.Result {
+	member := make(map[string]bool, len(bots))
+	for _, b := range bots {
+		member[b] = true
+	}
+
+	list := make([]rating.Result, 0, len(bots))
+	found := make(map[string]bool, len(bots))
+	last := 0 // rank in the report of the previous bot
+	for _, res := range rating.Results(r) {
+		if !member[res.Name] || found[res.Name] {
+			continue
+		}
+		rank := len(list) + 1
+		if len(list) > 0 && res.Rank == last {
+			rank = list[len(list)-1].Rank // tie
+		}
+		last = res.Rank
+		found[res.Name] = true
+		list = append(list, rating.Result{Name: res.Name, Rank: rank})
+	}
+
+	// bots without a cloud (no connection or wrong name)
+	missing := len(list) + 1
+	for _, b := range bots {
+		if !found[b] {
+			list = append(list, rating.Result{Name: b, Rank: missing})
+		}
+	}
+	return list
+}
+
+// forfeit returns the placements of a match that was not played: the bots that failed to start
+// share the last rank, the others share the win.
+func forfeit(bots []string, failed map[string]bool) []rating.Result {
+	last := 1
+	for _, b := range bots {
+		if !failed[b] {
+			last = 2
+		}
+	}
+	list := make([]rating.Result, 0, len(bots))
+	for _, b := range bots {
+		if failed[b] {
+			list = append(list, rating.Result{Name: b, Rank: last})
+		} else {
+			list = append(list, rating.Result{Name: b, Rank: 1})
+		}
+	}
+	return list
+}
+
+// points returns the points of each result (same order)
+func points(results []rating.Result) []float64 {
+	p := make([]float64, len(results))
+	for i, a := range results {
+		for j, b := range results {
+			if i == j {
+				continue
+			}
+			if a.Rank < b.Rank {
+				p[i] += 1
+			} else if a.Rank == b.Rank {
+				p[i] += 0.5
+			}
+		}
+	}
+	return p
+}
+
+// sortStandings orders the standings by points, wins and name
+func sortStandings(list []*Standing) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Points != list[j].Points {
+			return list[i].Points > list[j].Points
+		}
+		if list[i].Wins != list[j].Wins {
+			return list[i].Wins > list[j].Wins
+		}
+		return list[i].Name < list[j].Name
+	})
+}
+
+// Table returns the standings as markdown table.
+func (t *Tournament) Table() string {
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "| # | Bot | Points | Games | Wins | Byes | Errors |\n")
+	fmt.Fprintf(sb, "|--:|-----|-------:|------:|-----:|-----:|-------:|\n")
+	for i, s := range t.Standings {
+		fmt.Fprintf(sb, "| %d | %s | %.1f | %d | %d | %d | %d |\n", i+1, s.Name, s.Points, s.Games, s.Wins, s.Byes, s.Errors)
+	}
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+package tournament
+
+import (
+	"CloudWars/rating"
+	"CloudWars/stats"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundRobin(t *testing.T) {
+	exp := [][]int{{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}}
+	if got := roundRobin(4, 2); !reflect.DeepEqual(got, exp) {
+		t.Errorf("fail: %v", got)
+	}
+	exp = [][]int{{0, 1, 2}, {0, 1, 3}, {0, 2, 3}, {1, 2, 3}}
+	if got := roundRobin(4, 3); !reflect.DeepEqual(got, exp) {
+		t.Errorf("fail: %v", got)
+	}
+}
+
+func TestSwiss(t *testing.T) {
+	// first round: order of the bots, the last one gets a bye
+	played := make(map[[2]int]bool)
+	groups, bye := swiss([]float64{0, 0, 0, 0, 0}, []int{0, 0, 0, 0, 0}, played, 2)
+	if !reflect.DeepEqual(groups, [][]int{{0, 1}, {2, 3}}) || !reflect.DeepEqual(bye, []int{4}) {
+		t.Errorf("fail: %v %v", groups, bye)
+	}
+
+	// by points, without rematch
+	played[pair(0, 1)] = true
+	played[pair(2, 3)] = true
+	groups, bye = swiss([]float64{1, 0, 1, 0}, []int{0, 0, 0, 0}, played, 2)
+	if !reflect.DeepEqual(groups, [][]int{{0, 2}, {1, 3}}) || len(bye) != 0 {
+		t.Errorf("fail: %v %v", groups, bye)
+	}
+
+	// rematch if there is no other opponent
+	played[pair(0, 2)] = true
+	played[pair(1, 3)] = true
+	played[pair(0, 3)] = true
+	groups, _ = swiss([]float64{2, 0, 1, 1}, []int{0, 0, 0, 0}, played, 2)
+	if !reflect.DeepEqual(groups, [][]int{{0, 2}, {3, 1}}) {
+		t.Errorf("fail: %v", groups)
+	}
+
+	// no second bye
+	groups, bye = swiss([]float64{1, 1, 0}, []int{0, 0, 1}, make(map[[2]int]bool), 2)
+	if !reflect.DeepEqual(groups, [][]int{{0, 2}}) || !reflect.DeepEqual(bye, []int{1}) {
+		t.Errorf("fail: %v %v", groups, bye)
+	}
+}
+
+func TestResults(t *testing.T) {
+	r := &stats.Report{Winner: "B", Players: []*stats.Player{
+		{Name: "A", FinalVapor: 300},
+		{Name: "B", FinalVapor: 200},
+		{Name: "X", FinalVapor: 100}, // not part of the match
+		{Name: "C", FinalVapor: 0},
+	}}
+	exp := []rating.Result{{Name: "B", Rank: 1}, {Name: "A", Rank: 2}, {Name: "C", Rank: 3}, {Name: "D", Rank: 4}} // D is missing
+	if got := results(r, []string{"A", "B", "C", "D"}); !reflect.DeepEqual(got, exp) {
+		t.Errorf("fail: %v", got)
+	}
+
+	// points
+	if got := points(exp); !reflect.DeepEqual(got, []float64{3, 2, 1, 0}) {
+		t.Errorf("fail: %v", got)
+	}
+	if got := points([]rating.Result{{Name: "A", Rank: 1}, {Name: "B", Rank: 2}, {Name: "C", Rank: 2}}); !reflect.DeepEqual(got, []float64{2, 0.5, 0.5}) {
+		t.Errorf("fail: %v", got)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := Config{Bots: []Bot{{"A", []string{"a"}}, {"B", []string{"b"}}}}
+	c.defaults()
+	if err := c.validate(); err != nil || c.Format != FormatRoundRobin || c.Players != 2 || len(c.Seeds) != 1 {
+		t.Errorf("fail: %v %+v", err, c)
+	}
+
+	c.Players = 3
+	if err := c.validate(); err == nil {
+		t.Errorf("fail: not enough bots")
+	}
+	c.Players = 2
+	c.Bots[1].Name = "A"
+	if err := c.validate(); err == nil {
+		t.Errorf("fail: duplicate name")
+	}
+}
+
+func TestRun_forfeit(t *testing.T) {
+	t.Setenv("TOURNAMENT_HELPER", "1")
+	helper := []string{os.Args[0], "-test.run=TestHelperProcess", "--"}
+	c := Config{
+		Server: helper,
+		Output: t.TempDir(),
+		Bots: []Bot{
+			{"A", helper},
+			{"B", []string{"./does-not-exist-bot"}},
+		},
+	}
+
+	start := time.Now()
+	tr, err := Run(c, "")
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if d := time.Since(start); d > grace {
+		t.Errorf("fail: %v", d)
+	}
+	m := tr.Matches[0]
+	exp := []rating.Result{{Name: "A", Rank: 1}, {Name: "B", Rank: 2}}
+	if m.Error != "" || !reflect.DeepEqual(m.Results, exp) {
+		t.Errorf("fail: %+v", m)
+	}
+	if a, b := tr.standing("A"), tr.standing("B"); a.Points != 1 || a.Wins != 1 || b.Points != 0 || b.Games != 1 {
+		t.Errorf("fail: %+v %+v", a, b)
+	}
+}
+
+// TestHelperProcess is the server (listens on -port) or a bot (waits) of TestRun_forfeit.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("TOURNAMENT_HELPER") != "1" {
+		return
+	}
+	for _, arg := range os.Args {
+		if strings.HasPrefix(arg, "-port=") {
+			l, err := net.Listen("tcp", "localhost:"+strings.TrimPrefix(arg, "-port="))
+			if err != nil {
+				os.Exit(1)
+			}
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					os.Exit(1)
+				}
+				_ = conn.Close()
+			}
+		}
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}